@@ -25,6 +25,7 @@
 /*
  * Revision History:
  *     Initial: 2017/07/19        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package general
@@ -32,14 +33,30 @@ package general
 const (
 	// General
 	// Login session
-	SessionUserID   = "userid"
-	DuplicateEntry  = "Duplicate"
-	InvalidPassword = "match"
+	SessionUserID     = "userid"
+	SessionLoginAt    = "loginat"
+	SessionAdminID    = "adminid"
+	SessionOAuth      = "oauthstate"
+	SessionDeviceID   = "deviceid"
+	SessionSeenAt     = "seenat"
+	SessionVerifiedAt = "verifiedat"
+	DuplicateEntry    = "Duplicate"
+	InvalidPassword   = "match"
+
+	// SessionMaxLifetime is how long an idle session lives, in seconds.
+	SessionMaxLifetime = 3600
 
 	//User
 	// User Status
 	UserActive   = 0x0
 	UserInactive = 0x1
+	UserLocked   = 0x2
+	UserDeleted  = 0x3
+
+	// Admin
+	// Admin Status
+	AdminActive   = 0x0
+	AdminInactive = 0x1
 
 	// sex
 	Sex   = 0x0
@@ -56,6 +73,10 @@ const (
 	ProInCart    = 0x0
 	ProNotInCart = 0x1
 
+	//CartPro pay status, cart items stay behind marked bought once ordered
+	ProNotBought = 0x0
+	ProBought    = 0x1
+
 	// Category
 	//Category Status
 	CategoryNotUse = 0x0
@@ -67,6 +88,10 @@ const (
 	OrderFinished   = 0x1
 	OrderCanceled   = 0x2
 	OrderGetAll     = 0x3
+	OrderShipped    = 0x4
+	OrderReceived   = 0x5
+	OrderReturning  = 0x6
+	OrderSettled    = 0x7 // paid, not shipped yet
 
 	//Order Payment
 	OrderPaid   = 0x1
@@ -82,6 +107,26 @@ const (
 	ProductImage       = 0x1
 	ProductDetailImage = 0x2
 
+	// Product Import Job Status
+	ImportQueued  = 0x0
+	ImportRunning = 0x1
+	ImportDone    = 0x2
+	ImportFailed  = 0x3
+
+	// Merchandising
+	// Slot Status
+	SlotOnUse  = 0x0
+	SlotNotUse = 0x1
+
+	// Slot Kind
+	SlotBanner       = 0x0
+	SlotFeatured     = 0x1
+	SlotCategoryTile = 0x2
+
+	// Slot Page
+	SlotPageHome = "home"
+	SlotPageMine = "mypage"
+
 	//Pay
 	//Pay Way
 	PayOnline  = 0x0
@@ -25,43 +25,92 @@
 /*
  * Revision History:
  *     Initial: 2017/07/20        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package general
 
 import (
-	"github.com/labstack/echo"
-	"github.com/labstack/gommon/log"
+	"fmt"
 	"net/http"
-)
 
-var (
-	code = http.StatusInternalServerError
-	msg  string
+	"github.com/labstack/echo"
+
+	"ShopApi/general/errcode"
+	"ShopApi/metrics"
 )
 
+// httpErrors maps the errors echo raises itself to registered codes,
+// statuses not listed answer with errcode.ErrBadRequest or ErrInternal.
+var httpErrors = map[int]*errcode.Code{
+	http.StatusNotFound:             errcode.ErrRouteNotFound,
+	http.StatusMethodNotAllowed:     errcode.ErrMethodNotAllowed,
+	http.StatusUnsupportedMediaType: errcode.ErrUnsupportedMediaType,
+}
+
+// EchoRestfulErrorHandler writes err as an ErrorResp. Everything it needs is
+// kept in locals, it runs concurrently for every failing request.
 func EchoRestfulErrorHandler(err error, c echo.Context) {
-	log.Error(err)
+	resp := localize(toErrorResp(err), c)
+	metrics.Errors.WithLabelValues(resp.Key).Inc()
 
-	if resp, ok := err.(*ErrorResp); ok {
-		code = resp.Code
-		msg = resp.Message
+	RequestLog(c).Error("[ERROR] Request failed:", err)
+
+	if c.Response().Committed {
+		return
+	}
+
+	if c.Request().Method == echo.HEAD {
+		err = c.NoContent(resp.Status())
 	} else {
-		msg = http.StatusText(code)
+		err = c.JSON(resp.Status(), resp)
 	}
 
-	if !c.Response().Committed {
-		if c.Request().Method == echo.HEAD {
-			err := c.NoContent(code)
-			if err != nil {
-				c.Logger().Error(err)
-			}
-		} else {
-			err := c.JSON(code, NewErrorWithMessage(code, msg))
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
+
+// localize replaces the message with the catalogue text in the client's
+// language. The original message is kept as detail only for bad requests,
+// other errors may carry database or driver internals.
+func localize(resp *ErrorResp, c echo.Context) *ErrorResp {
+	out := *resp
+
+	out.Detail = ""
+	if out.status == http.StatusBadRequest {
+		out.Detail = resp.Message
+	}
+
+	code := resp.code
+	if code == nil {
+		code = errcode.ErrInternal
+	}
 
-			if err != nil {
-				c.Logger().Error(err)
+	out.Message = code.Message(Language(c))
+	out.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+
+	return &out
+}
+
+func toErrorResp(err error) *ErrorResp {
+	switch e := err.(type) {
+	case *ErrorResp:
+		return e
+	case *echo.HTTPError:
+		code, ok := httpErrors[e.Code]
+		if !ok {
+			code = errcode.ErrBadRequest
+			if e.Code >= http.StatusInternalServerError {
+				code = errcode.ErrInternal
 			}
 		}
+
+		resp := NewErrorWithMessage(code, fmt.Sprint(e.Message))
+		resp.status = e.Code
+
+		return resp
+	default:
+		return NewErrorWithMessage(errcode.ErrInternal, http.StatusText(http.StatusInternalServerError))
 	}
 }
@@ -0,0 +1,254 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package general
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/general/errcode"
+)
+
+type handledError struct {
+	status int
+	body   ErrorResp
+	raw    string
+}
+
+func handle(t *testing.T, method, lang string, err error) handledError {
+	e := echo.New()
+
+	req := httptest.NewRequest(method, "/api/v1/test", nil)
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+
+	EchoRestfulErrorHandler(err, c)
+
+	out := handledError{status: rec.Code, raw: rec.Body.String()}
+	if out.raw != "" {
+		if jerr := json.Unmarshal(rec.Body.Bytes(), &out.body); jerr != nil {
+			t.Errorf("body %q: %v", out.raw, jerr)
+		}
+	}
+
+	return out
+}
+
+func TestEchoRestfulErrorHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		lang    string
+		status  int
+		code    int
+		key     string
+		message string
+		detail  string
+	}{
+		{
+			name:    "bad request keeps the detail",
+			err:     NewErrorWithMessage(errcode.ErrInvalidParams, "Key: 'Login.Name' failed"),
+			status:  http.StatusBadRequest,
+			code:    errcode.ErrInvalidParams.Value,
+			key:     "invalid_params",
+			message: "请求参数错误",
+			detail:  "Key: 'Login.Name' failed",
+		},
+		{
+			name:    "english",
+			err:     NewErrorWithMessage(errcode.ErrMustLogin, "User Must Login."),
+			lang:    "en-US,en;q=0.9",
+			status:  http.StatusUnauthorized,
+			code:    errcode.ErrMustLogin.Value,
+			key:     "must_login",
+			message: "Please log in first",
+		},
+		{
+			name:    "chinese preferred by weight",
+			err:     NewErrorWithMessage(errcode.ErrMustAdmin, "Admin Must Login."),
+			lang:    "en;q=0.5,zh-CN",
+			status:  http.StatusForbidden,
+			code:    errcode.ErrMustAdmin.Value,
+			key:     "must_admin",
+			message: "需要管理员权限",
+		},
+		{
+			name:    "database error hides the driver message",
+			err:     NewErrorWithMessage(errcode.ErrMysql, "Error 1146: Table 'shop.users' doesn't exist"),
+			lang:    "en",
+			status:  http.StatusInternalServerError,
+			code:    errcode.ErrMysql.Value,
+			key:     "database",
+			message: "The server is busy, please try again later",
+		},
+		{
+			name:    "conflict",
+			err:     NewErrorWithMessage(errcode.ErrDuplicate, "Duplicate entry"),
+			status:  http.StatusConflict,
+			code:    errcode.ErrDuplicate.Value,
+			key:     "duplicate",
+			message: "数据已存在",
+		},
+		{
+			name:    "unknown route",
+			err:     echo.ErrNotFound,
+			lang:    "en",
+			status:  http.StatusNotFound,
+			code:    errcode.ErrRouteNotFound.Value,
+			key:     "route_not_found",
+			message: "No such API",
+		},
+		{
+			name:    "wrong method",
+			err:     echo.ErrMethodNotAllowed,
+			status:  http.StatusMethodNotAllowed,
+			code:    errcode.ErrMethodNotAllowed.Value,
+			key:     "method_not_allowed",
+			message: errcode.ErrMethodNotAllowed.Message(errcode.LangZH),
+		},
+		{
+			name:    "unsupported media type",
+			err:     echo.ErrUnsupportedMediaType,
+			status:  http.StatusUnsupportedMediaType,
+			code:    errcode.ErrUnsupportedMediaType.Value,
+			key:     "unsupported_media_type",
+			message: errcode.ErrUnsupportedMediaType.Message(errcode.LangZH),
+		},
+		{
+			name:    "other echo 4xx keeps its status",
+			err:     echo.NewHTTPError(http.StatusRequestEntityTooLarge, "too large"),
+			status:  http.StatusRequestEntityTooLarge,
+			code:    errcode.ErrBadRequest.Value,
+			key:     "bad_request",
+			message: errcode.ErrBadRequest.Message(errcode.LangZH),
+		},
+		{
+			name:    "echo 5xx",
+			err:     echo.NewHTTPError(http.StatusServiceUnavailable, "down"),
+			status:  http.StatusServiceUnavailable,
+			code:    errcode.ErrInternal.Value,
+			key:     "internal",
+			message: "服务器内部错误",
+		},
+		{
+			name:    "plain error",
+			err:     errors.New("sql: database is closed"),
+			lang:    "en",
+			status:  http.StatusInternalServerError,
+			code:    errcode.ErrInternal.Value,
+			key:     "internal",
+			message: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		got := handle(t, echo.POST, tt.lang, tt.err)
+
+		if got.status != tt.status {
+			t.Errorf("%s: HTTP %d, want %d", tt.name, got.status, tt.status)
+		}
+
+		want := ErrorResp{
+			Code:      tt.code,
+			Key:       tt.key,
+			Message:   tt.message,
+			Detail:    tt.detail,
+			RequestID: "req-1",
+		}
+		if got.body != want {
+			t.Errorf("%s: body %+v, want %+v", tt.name, got.body, want)
+		}
+	}
+}
+
+func TestEchoRestfulErrorHandlerHead(t *testing.T) {
+	got := handle(t, echo.HEAD, "", NewErrorWithMessage(errcode.ErrNotFound, "not found"))
+
+	if got.status != http.StatusNotFound {
+		t.Errorf("HTTP %d, want %d", got.status, http.StatusNotFound)
+	}
+
+	if got.raw != "" {
+		t.Errorf("HEAD answered with body %q", got.raw)
+	}
+}
+
+func TestEchoRestfulErrorHandlerCommitted(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(echo.GET, "/", nil), rec)
+
+	c.String(http.StatusOK, "partial")
+	EchoRestfulErrorHandler(NewErrorWithMessage(errcode.ErrMysql, "late"), c)
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "partial" {
+		t.Errorf("committed response changed to %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// The handler runs for many requests at once and must not share state
+// between them, run with -race.
+func TestEchoRestfulErrorHandlerConcurrent(t *testing.T) {
+	shared := NewErrorWithMessage(errcode.ErrInvalidParams, "bad field")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			lang, want := "zh-CN", "请求参数错误"
+			if i%2 == 0 {
+				lang, want = "en", "Invalid request parameters"
+			}
+
+			got := handle(t, echo.POST, lang, shared)
+			if got.body.Message != want || got.body.Detail != "bad field" {
+				t.Errorf("request %d got %+v", i, got.body)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if shared.Message != "bad field" {
+		t.Errorf("handler changed the error it was given: %q", shared.Message)
+	}
+}
@@ -25,26 +25,52 @@
 /*
  * Revision History:
  *     Initial: 2017/08/07       Zhang Zizhao
+ *     Modify : 2026/10/19
  */
 
 package errcode
 
+import (
+	"net/http"
+)
+
 const (
 	//Createorder
-	ErrCreateOrderSucceed       = 0x0
-	ErrCreateOrderInvalidParams = 0x1
-	ErrAddressNotFound          = 0x2
+	ErrCreateOrderSucceed = 0x0
 
 	//GetOrders
-	ErrGetOrdersSucceed       = 0x0
-	ErrGetOrdersInvalidParams = 0x1
-	ErrInvalidOrdersStatus    = 0x2
+	ErrGetOrdersSucceed = 0x0
 
 	//GetOrder
-	ErrGetOrderSucceed       = 0x0
-	ErrGetOrderInvalidParams = 0x1
+	ErrGetOrderSucceed = 0x0
 
 	//ChangeStatus
-	ErrChangeOrderSucceed       = 0x0
-	ErrChangeOrderInvalidParams = 0x1
+	ErrChangeOrderSucceed = 0x0
+
+	// ChangeOrderAddress
+	ChangeOrderAddressSucceed = 0x0
+)
+
+var (
+	//Createorder
+	ErrCreateOrderInvalidParams = register(0x1, http.StatusBadRequest, "order.create.invalid_params")
+	ErrAddressNotFound          = register(0x2, http.StatusNotFound, "order.create.address_not_found")
+	ErrOrderCartNotFound        = register(0x3, http.StatusNotFound, "order.create.cart_not_found")
+
+	//GetOrders
+	ErrGetOrdersInvalidParams = register(0x1, http.StatusBadRequest, "order.list.invalid_params")
+	ErrInvalidOrdersStatus    = register(0x2, http.StatusBadRequest, "order.list.invalid_status")
+
+	//GetOrder
+	ErrGetOrderInvalidParams = register(0x1, http.StatusBadRequest, "order.get.invalid_params")
+
+	//ChangeStatus
+	ErrChangeOrderInvalidParams = register(0x1, http.StatusBadRequest, "order.change_status.invalid_params")
+	ErrChangeOrderNotAllowed    = register(0x2, http.StatusConflict, "order.change_status.not_allowed")
+
+	// ChangeOrderAddress
+	ErrChangeOrderAddressInvalidParams   = register(0x1, http.StatusBadRequest, "order.change_address.invalid_params")
+	ErrChangeOrderAddressNotFound        = register(0x2, http.StatusNotFound, "order.change_address.not_found")
+	ErrChangeOrderAddressAddressNotFound = register(0x3, http.StatusNotFound, "order.change_address.address_not_found")
+	ErrChangeOrderAddressNotEditable     = register(0x4, http.StatusConflict, "order.change_address.not_editable")
 )
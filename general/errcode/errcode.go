@@ -25,25 +25,99 @@
 /*
  * Revision History:
  *     Initial: 2017/05/14        Feng Yifei
+ *     Modify : 2026/10/19
  */
 
 package errcode
 
+import (
+	"net/http"
+	"sort"
+)
+
+// Code is an application error. Value is the business code clients already
+// read from the "status" field, Status the HTTP status the error is answered
+// with and Key a stable name programs can match on, unique across modules.
+type Code struct {
+	Value  int
+	Status int
+	Key    string
+}
+
+var registry = map[string]*Code{}
+
+func register(value, status int, key string) *Code {
+	if _, ok := registry[key]; ok {
+		panic("errcode: duplicate key " + key)
+	}
+
+	code := &Code{
+		Value:  value,
+		Status: status,
+		Key:    key,
+	}
+	registry[key] = code
+
+	return code
+}
+
+// Lookup returns the error registered under key.
+func Lookup(key string) (*Code, bool) {
+	code, ok := registry[key]
+	return code, ok
+}
+
+// All returns every registered error ordered by key.
+func All() []*Code {
+	codes := make([]*Code, 0, len(registry))
+	for _, code := range registry {
+		codes = append(codes, code)
+	}
+
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i].Key < codes[j].Key
+	})
+
+	return codes
+}
+
 const (
 	// General
-	ErrSucceed       = 0x0
-	ErrInvalidParams = 0x1
+	ErrSucceed = 0x0
+)
+
+var (
+	// General
+	ErrInvalidParams = register(0x1, http.StatusBadRequest, "invalid_params")
+
+	ErrDuplicate = register(0x3, http.StatusConflict, "duplicate")
+	ErrMustLogin = register(0x4, http.StatusUnauthorized, "must_login")
+	ErrMustAdmin = register(0x5, http.StatusForbidden, "must_admin")
+	ErrMysql     = register(0xff, http.StatusInternalServerError, "database")
+	ErrMongo     = register(0xfe, http.StatusInternalServerError, "mongo")
+
+	ErrMustVerify = register(0x6, http.StatusForbidden, "must_verify")
 
-	ErrDuplicate = 0x3
-	ErrMustLogin = 0x4
-	ErrMysql     = 0xff
-	ErrMongo     = 0xfe
 	//User
-	ErrUserNotFound    = 0x2
-	ErrInvalidPassword = 0x3
+	ErrUserNotFound    = register(0x2, http.StatusNotFound, "user_not_found")
+	ErrInvalidPassword = register(0x3, http.StatusUnauthorized, "invalid_password")
+
+	ErrNotFound          = register(0xa, http.StatusNotFound, "not_found")
+	ErrBind              = register(0xe, http.StatusBadRequest, "bind")
+	ErrInformation       = register(0xf, http.StatusBadRequest, "information")
+	ErrAddressIdNotFound = register(0x9, http.StatusNotFound, "address_id_not_found")
+
+	// Raised outside of handlers
+	ErrInternal             = register(0xfd, http.StatusInternalServerError, "internal")
+	ErrRouteNotFound        = register(0xfc, http.StatusNotFound, "route_not_found")
+	ErrMethodNotAllowed     = register(0xfb, http.StatusMethodNotAllowed, "method_not_allowed")
+	ErrUnsupportedMediaType = register(0xfa, http.StatusUnsupportedMediaType, "unsupported_media_type")
+	ErrBadRequest           = register(0xf9, http.StatusBadRequest, "bad_request")
+	ErrNotReady             = register(0xf8, http.StatusServiceUnavailable, "not_ready")
+	ErrTooManyRequests      = register(0xf7, http.StatusTooManyRequests, "too_many_requests")
 
-	ErrNotFound          = 0xa
-	ErrBind              = 0xe
-	ErrInformation       = 0xf
-	ErrAddressIdNotFound = 0x9
+	// Idempotency-Key
+	ErrIdempotencyInvalidKey = register(0xf6, http.StatusBadRequest, "idempotency.invalid_key")
+	ErrIdempotencyKeyReused  = register(0xf5, http.StatusUnprocessableEntity, "idempotency.key_reused")
+	ErrIdempotencyInProgress = register(0xf4, http.StatusConflict, "idempotency.in_progress")
 )
@@ -0,0 +1,152 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package errcode
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	codes := All()
+	if len(codes) == 0 {
+		t.Fatal("no error registered")
+	}
+
+	for i, code := range codes {
+		if code.Key == "" || strings.TrimSpace(code.Key) != code.Key {
+			t.Errorf("code %d has key %q", code.Value, code.Key)
+		}
+
+		if code.Value == ErrSucceed {
+			t.Errorf("%s uses the success value", code.Key)
+		}
+
+		if code.Status < http.StatusBadRequest || code.Status > 599 {
+			t.Errorf("%s answers with HTTP %d, want 4xx or 5xx", code.Key, code.Status)
+		}
+
+		if http.StatusText(code.Status) == "" {
+			t.Errorf("%s answers with unknown HTTP status %d", code.Key, code.Status)
+		}
+
+		if i > 0 && codes[i-1].Key >= code.Key {
+			t.Errorf("All is not ordered by key: %s before %s", codes[i-1].Key, code.Key)
+		}
+
+		found, ok := Lookup(code.Key)
+		if !ok || found != code {
+			t.Errorf("Lookup(%q) = %v, %v", code.Key, found, ok)
+		}
+	}
+
+	if _, ok := Lookup("no.such.key"); ok {
+		t.Error("Lookup found an unregistered key")
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a key twice did not panic")
+		}
+	}()
+
+	register(0x1, http.StatusBadRequest, ErrInvalidParams.Key)
+}
+
+func TestStatuses(t *testing.T) {
+	tests := []struct {
+		code   *Code
+		status int
+	}{
+		{ErrInvalidParams, http.StatusBadRequest},
+		{ErrDuplicate, http.StatusConflict},
+		{ErrMustLogin, http.StatusUnauthorized},
+		{ErrMustAdmin, http.StatusForbidden},
+		{ErrNotFound, http.StatusNotFound},
+		{ErrMysql, http.StatusInternalServerError},
+		{ErrMongo, http.StatusInternalServerError},
+		{ErrInternal, http.StatusInternalServerError},
+		{ErrRouteNotFound, http.StatusNotFound},
+		{ErrMethodNotAllowed, http.StatusMethodNotAllowed},
+		{ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{ErrBadRequest, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		if tt.code.Status != tt.status {
+			t.Errorf("%s answers with %d, want %d", tt.code.Key, tt.code.Status, tt.status)
+		}
+	}
+}
+
+// Every registered error must have its own text in both languages.
+func TestEveryKeyHasMessage(t *testing.T) {
+	for _, code := range All() {
+		m, ok := messages[code.Key]
+		if !ok {
+			t.Errorf("%s has no message", code.Key)
+			continue
+		}
+
+		if m.zh == "" || m.en == "" {
+			t.Errorf("%s misses a translation: %+v", code.Key, m)
+		}
+	}
+
+	for key := range messages {
+		if _, ok := Lookup(key); !ok {
+			t.Errorf("message %s belongs to no registered error", key)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		code *Code
+		lang string
+		want string
+	}{
+		{"zh", ErrMustLogin, LangZH, "请先登录"},
+		{"en", ErrMustLogin, LangEN, "Please log in first"},
+		{"unknown language", ErrMustLogin, "fr", "请先登录"},
+		{"unregistered 4xx", &Code{Value: 0x1, Status: http.StatusTeapot, Key: "test.teapot"}, LangEN, messages["bad_request"].en},
+		{"unregistered 5xx", &Code{Value: 0x1, Status: http.StatusBadGateway, Key: "test.gateway"}, LangZH, messages["internal"].zh},
+	}
+
+	for _, tt := range tests {
+		if got := tt.code.Message(tt.lang); got != tt.want {
+			t.Errorf("%s: Message = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
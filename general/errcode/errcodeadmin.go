@@ -0,0 +1,94 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package errcode
+
+import (
+	"net/http"
+)
+
+const (
+	// AdminLogin
+	AdminLoginSucceed = 0x0
+
+	// AdminLogout
+	AdminLogoutSucceed = 0x0
+
+	// SuspendUser
+	SuspendUserSucceed = 0x0
+
+	// ReactivateUser
+	ReactivateUserSucceed = 0x0
+
+	// AdminListOrders
+	AdminListOrdersSucceed = 0x0
+
+	// AdminGetOrder
+	AdminGetOrderSucceed = 0x0
+
+	// AddOrderNote
+	AddOrderNoteSucceed = 0x0
+
+	// AdminChangeOrders
+	AdminChangeOrdersSucceed = 0x0
+)
+
+var (
+	// AdminLogin
+	ErrAdminLoginInvalidParams = register(0x1, http.StatusBadRequest, "admin.login.invalid_params")
+	ErrAdminLoginFailed        = register(0x2, http.StatusUnauthorized, "admin.login.failed")
+
+	// AdminLogout
+	ErrAdminLogout = register(0x1, http.StatusInternalServerError, "admin.logout.failed")
+
+	// SuspendUser
+	ErrSuspendUserInvalidParams = register(0x1, http.StatusBadRequest, "admin.suspend_user.invalid_params")
+	ErrSuspendUserNotFound      = register(0x2, http.StatusNotFound, "admin.suspend_user.not_found")
+
+	// ReactivateUser
+	ErrReactivateUserInvalidParams = register(0x1, http.StatusBadRequest, "admin.reactivate_user.invalid_params")
+	ErrReactivateUserNotFound      = register(0x2, http.StatusNotFound, "admin.reactivate_user.not_found")
+
+	// AdminListOrders
+	ErrAdminListOrdersInvalidParams = register(0x1, http.StatusBadRequest, "admin.list_orders.invalid_params")
+
+	// AdminGetOrder
+	ErrAdminGetOrderInvalidParams = register(0x1, http.StatusBadRequest, "admin.get_order.invalid_params")
+	ErrAdminGetOrderNotFound      = register(0x2, http.StatusNotFound, "admin.get_order.not_found")
+
+	// AddOrderNote
+	ErrAddOrderNoteInvalidParams = register(0x1, http.StatusBadRequest, "admin.add_order_note.invalid_params")
+	ErrAddOrderNoteNotFound      = register(0x2, http.StatusNotFound, "admin.add_order_note.not_found")
+
+	// AdminChangeOrders
+	ErrAdminChangeOrdersInvalidParams = register(0x1, http.StatusBadRequest, "admin.change_orders.invalid_params")
+
+	// ExportOrders
+	ErrExportOrdersInvalidParams = register(0x1, http.StatusBadRequest, "admin.export_orders.invalid_params")
+)
@@ -25,33 +25,50 @@
 /*
  * Revision History:
  *     Initial: 2017/08/09       Zhang Zizhao
+ *     Modify : 2026/10/19
  */
 
 package errcode
 
+import (
+	"net/http"
+)
+
 const (
 	//PutIn
-	CreateSucceed               = 0x0
-	ErrCartPutInInvalidParams   = 0x1
-	ErrCartPutInProductNotFound = 0x2
-	ErrCartPutInDatabase        = 0x3
+	CreateSucceed = 0x0
+
+	//Delete
+	CartDeleteSucceed = 0x0
+
+	// CartsDelete
+	CartsDeleteSucceed = 0x0
+
+	//Alter
+	AlterCartSucceed = 0x0
+
+	//Browse
+	BrowseCartSucceed = 0x0
+)
+
+var (
+	//PutIn
+	ErrCartPutInInvalidParams   = register(0x1, http.StatusBadRequest, "cart.put_in.invalid_params")
+	ErrCartPutInProductNotFound = register(0x2, http.StatusNotFound, "cart.put_in.product_not_found")
+	ErrCartPutInDatabase        = register(0x3, http.StatusInternalServerError, "cart.put_in.database")
 
 	//Delete
-	CartDeleteSucceed            = 0x0
-	ErrCartDeleteInvalidParams   = 0x1
-	ErrCartDeleteProductNotFound = 0x3
+	ErrCartDeleteInvalidParams   = register(0x1, http.StatusBadRequest, "cart.delete.invalid_params")
+	ErrCartDeleteProductNotFound = register(0x3, http.StatusNotFound, "cart.delete.product_not_found")
 
 	// CartsDelete
-	CartsDeleteSucceed             = 0x0
-	ErrCartsDeleteErrInvalidParams = 0x1
+	ErrCartsDeleteErrInvalidParams = register(0x1, http.StatusBadRequest, "cart.delete_many.invalid_params")
 
 	//Alter
-	AlterCartSucceed            = 0x0
-	ErrAlterCartInvalidParams   = 0x1
-	ErrAlterCartProductNotFound = 0x2
+	ErrAlterCartInvalidParams   = register(0x1, http.StatusBadRequest, "cart.alter.invalid_params")
+	ErrAlterCartProductNotFound = register(0x2, http.StatusNotFound, "cart.alter.product_not_found")
 
 	//Browse
-	BrowseCartSucceed      = 0x0
-	ErrBrowseInvalidParams = 0x1
-	ErrBrowseCartNotFound  = 0x2
+	ErrBrowseInvalidParams = register(0x1, http.StatusBadRequest, "cart.browse.invalid_params")
+	ErrBrowseCartNotFound  = register(0x2, http.StatusNotFound, "cart.browse.not_found")
 )
@@ -0,0 +1,75 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package errcode
+
+import (
+	"net/http"
+)
+
+const (
+	// OAuthAuthorize
+	OAuthAuthorizeSucceed = 0x0
+
+	// OAuthLogin
+	OAuthLoginSucceed = 0x0
+
+	// SendBindPhoneCode
+	SendBindPhoneCodeSucceed = 0x0
+
+	// BindPhone
+	BindPhoneSucceed = 0x0
+
+	// UnbindPhone
+	UnbindPhoneSucceed = 0x0
+)
+
+var (
+	// OAuthAuthorize
+	ErrOAuthUnknownProvider = register(0x1, http.StatusNotFound, "oauth.unknown_provider")
+
+	// OAuthLogin
+	ErrOAuthLoginInvalidParams = register(0x2, http.StatusBadRequest, "oauth.login.invalid_params")
+	ErrOAuthLoginInvalidState  = register(0x3, http.StatusBadRequest, "oauth.login.invalid_state")
+	ErrOAuthLoginProvider      = register(0x4, http.StatusBadGateway, "oauth.login.provider")
+
+	// SendBindPhoneCode
+	ErrSendBindPhoneCodeInvalidParams = register(0x1, http.StatusBadRequest, "oauth.send_bind_code.invalid_params")
+	ErrSendBindPhoneCodeUnavailable   = register(0x2, http.StatusServiceUnavailable, "oauth.send_bind_code.unavailable")
+
+	// BindPhone
+	ErrBindPhoneInvalidParams = register(0x1, http.StatusBadRequest, "oauth.bind_phone.invalid_params")
+	ErrBindPhoneAlreadyBound  = register(0x2, http.StatusConflict, "oauth.bind_phone.already_bound")
+	ErrBindPhoneDuplicate     = register(0x3, http.StatusConflict, "oauth.bind_phone.duplicate")
+	ErrBindPhoneInvalidCode   = register(0x4, http.StatusBadRequest, "oauth.bind_phone.invalid_code")
+
+	// UnbindPhone
+	ErrUnbindPhoneNotBound   = register(0x1, http.StatusConflict, "oauth.unbind_phone.not_bound")
+	ErrUnbindPhoneLastSignIn = register(0x2, http.StatusConflict, "oauth.unbind_phone.last_sign_in")
+)
@@ -25,35 +25,71 @@
 /*
  * Revision History:
  *     Initial: 2017/08/05       Ai Hao
+ *     Modify : 2026/10/19
  */
 
 package errcode
 
+import (
+	"net/http"
+)
+
 const (
 	// Create
-	CreateProductSucceed          = 0x0
-	ErrCreateProductInvalidParams = 0x1
-	ErrCreateProductDatabase      = 0x2
+	CreateProductSucceed = 0x0
+
+	// GetList
+	GetListSucceed = 0x0
+
+	// GetProductListByCategory
+	GetProductListByCategorySucceed = 0x0
+
+	// ChangeStatus
+	ChangeStatusSucceed = 0x0
+
+	// GetProInfo
+	GetProInfoSucceed = 0x0
+
+	// ChangeCategory
+	ChangeCategorySucceed = 0x0
+
+	// ImportProducts
+	ImportProductsSucceed = 0x0
+
+	// GetImportJob
+	GetImportJobSucceed = 0x0
+)
+
+var (
+	// Create
+	ErrCreateProductInvalidParams = register(0x1, http.StatusBadRequest, "product.create.invalid_params")
+	ErrCreateProductDatabase      = register(0x2, http.StatusInternalServerError, "product.create.database")
 
 	// GetList
-	GetListSucceed     = 0x0
-	ErrGetListDatabase = 0x1
+	ErrGetListDatabase = register(0x1, http.StatusInternalServerError, "product.list.database")
 
 	// GetProductListByCategory
-	GetProductListByCategorySucceed          = 0x0
-	ErrGetProductListByCategoryInvalidParams = 0x1
-	ErrGetProductListByCategoryNotFound      = 0x2
+	ErrGetProductListByCategoryInvalidParams = register(0x1, http.StatusBadRequest, "product.by_category.invalid_params")
+	ErrGetProductListByCategoryNotFound      = register(0x2, http.StatusNotFound, "product.by_category.not_found")
 
 	// ChangeStatus
-	ChangeStatusSucceed             = 0x0
-	ErrChangeProStatusInvalidParams = 0x1
+	ErrChangeProStatusInvalidParams = register(0x1, http.StatusBadRequest, "product.change_status.invalid_params")
 
 	// GetProInfo
-	GetProInfoSucceed          = 0x0
-	ErrGetProInfoInvalidParams = 0x1
-	ErrGetProInfoNotFound      = 0x2
+	ErrGetProInfoInvalidParams = register(0x1, http.StatusBadRequest, "product.info.invalid_params")
+	ErrGetProInfoNotFound      = register(0x2, http.StatusNotFound, "product.info.not_found")
 
 	// ChangeCategory
-	ChangeCategorySucceed    = 0x0
-	ErrCategoryInvalidParams = 0x1
+	ErrCategoryInvalidParams = register(0x1, http.StatusBadRequest, "product.change_category.invalid_params")
+
+	// ImportProducts
+	ErrImportProductsInvalidParams = register(0x1, http.StatusBadRequest, "product.import.invalid_params")
+	ErrImportProductsTooLarge      = register(0x2, http.StatusRequestEntityTooLarge, "product.import.too_large")
+
+	// GetImportJob
+	ErrGetImportJobInvalidParams = register(0x1, http.StatusBadRequest, "product.import_job.invalid_params")
+	ErrGetImportJobNotFound      = register(0x2, http.StatusNotFound, "product.import_job.not_found")
+
+	// ExportProducts
+	ErrExportProductsInvalidParams = register(0x1, http.StatusBadRequest, "product.export.invalid_params")
 )
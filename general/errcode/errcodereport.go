@@ -0,0 +1,56 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package errcode
+
+import (
+	"net/http"
+)
+
+const (
+	// SalesReport
+	SalesReportSucceed = 0x0
+
+	// TopProductsReport
+	TopProductsReportSucceed = 0x0
+
+	// TopCategoriesReport
+	TopCategoriesReportSucceed = 0x0
+)
+
+var (
+	// SalesReport
+	ErrSalesReportInvalidParams = register(0x1, http.StatusBadRequest, "report.sales.invalid_params")
+
+	// TopProductsReport
+	ErrTopProductsReportInvalidParams = register(0x1, http.StatusBadRequest, "report.top_products.invalid_params")
+
+	// TopCategoriesReport
+	ErrTopCategoriesReportInvalidParams = register(0x1, http.StatusBadRequest, "report.top_categories.invalid_params")
+)
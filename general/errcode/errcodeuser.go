@@ -25,44 +25,128 @@
 /*
  * Revision History:
  *     Initial: 2017/05/14        Li Zebang
+ *     Modify : 2026/10/19
  */
 
 package errcode
 
+import (
+	"net/http"
+)
+
 const (
 	// Register
-	RegisterSucceed          = 0x0
-	ErrRegisterInvalidParams = 0x1
-	ErrRegisterUserDuplicate = 0x2
+	RegisterSucceed = 0x0
 
 	// Login
-	LoginSucceed            = 0x0
-	ErrLoginInvalidParams   = 0x1
-	ErrLoginUserNotFound    = 0x2
-	ErrLoginInvalidPassword = 0x3
+	LoginSucceed = 0x0
 
 	// Logout
 	LogoutSucceed = 0x0
-	ErrLogout     = 0x1
 
 	// GetUserInfo
-	GetUserInfoSucceed          = 0x0
-	ErrGetUserInfoInvalidParams = 0x1
+	GetUserInfoSucceed = 0x0
 
 	// ChangeUserInfo
-	ChangeUserInfoSucceed          = 0x0
-	ErrChangeUserInfoInvalidParams = 0x1
+	ChangeUserInfoSucceed = 0x0
 
 	// ChangeUserAvatar
-	ChangeUserAvatarSucceed          = 0x0
-	ErrChangeUserAvatarInvalidParams = 0x1
+	ChangeUserAvatarSucceed = 0x0
 
 	// ChangePhone
-	ChangePhoneSucceed          = 0x0
-	ErrChangePhoneInvalidParams = 0x1
-	ErrChangePhoneDuplicate     = 0x2
+	ChangePhoneSucceed = 0x0
 
 	// ChangePassword
-	ChangePasswordSucceed          = 0x0
-	ErrChangePasswordInvalidParams = 0x1
+	ChangePasswordSucceed = 0x0
+
+	// DeleteAccount
+	DeleteAccountSucceed = 0x0
+
+	// ListSessions
+	ListSessionsSucceed = 0x0
+
+	// LogoutSession
+	LogoutSessionSucceed = 0x0
+
+	// LogoutOtherSessions
+	LogoutOtherSessionsSucceed = 0x0
+
+	// GetVerifyStatus
+	GetVerifyStatusSucceed = 0x0
+
+	// SendVerifyCode
+	SendVerifyCodeSucceed = 0x0
+
+	// Verify
+	VerifySucceed = 0x0
+
+	// EnrollTOTP
+	EnrollTOTPSucceed = 0x0
+
+	// ConfirmTOTP
+	ConfirmTOTPSucceed = 0x0
+
+	// DisableTOTP
+	DisableTOTPSucceed = 0x0
+
+	// RegenerateBackupCodes
+	RegenerateBackupCodesSucceed = 0x0
+)
+
+var (
+	// Register
+	ErrRegisterInvalidParams = register(0x1, http.StatusBadRequest, "user.register.invalid_params")
+	ErrRegisterUserDuplicate = register(0x2, http.StatusConflict, "user.register.duplicate")
+
+	// Login
+	ErrLoginInvalidParams   = register(0x1, http.StatusBadRequest, "user.login.invalid_params")
+	ErrLoginUserNotFound    = register(0x2, http.StatusUnauthorized, "user.login.user_not_found")
+	ErrLoginInvalidPassword = register(0x3, http.StatusUnauthorized, "user.login.invalid_password")
+	ErrLoginLocked          = register(0x4, http.StatusTooManyRequests, "user.login.locked")
+	ErrLoginSuspended       = register(0x5, http.StatusForbidden, "user.login.suspended")
+
+	// Logout
+	ErrLogout = register(0x1, http.StatusInternalServerError, "user.logout.failed")
+
+	// GetUserInfo
+	ErrGetUserInfoInvalidParams = register(0x1, http.StatusBadRequest, "user.get_info.invalid_params")
+
+	// ChangeUserInfo
+	ErrChangeUserInfoInvalidParams = register(0x1, http.StatusBadRequest, "user.change_info.invalid_params")
+
+	// ChangeUserAvatar
+	ErrChangeUserAvatarInvalidParams = register(0x1, http.StatusBadRequest, "user.change_avatar.invalid_params")
+
+	// ChangePhone
+	ErrChangePhoneInvalidParams = register(0x1, http.StatusBadRequest, "user.change_phone.invalid_params")
+	ErrChangePhoneDuplicate     = register(0x2, http.StatusConflict, "user.change_phone.duplicate")
+
+	// ChangePassword
+	ErrChangePasswordInvalidParams = register(0x1, http.StatusBadRequest, "user.change_password.invalid_params")
+
+	// DeleteAccount
+	ErrDeleteAccountInvalidParams = register(0x1, http.StatusBadRequest, "user.delete.invalid_params")
+
+	// LogoutSession
+	ErrLogoutSessionInvalidParams = register(0x1, http.StatusBadRequest, "user.logout_session.invalid_params")
+	ErrLogoutSessionNotFound      = register(0x2, http.StatusNotFound, "user.logout_session.not_found")
+
+	// SendVerifyCode
+	ErrSendVerifyCodeNoPhone     = register(0x1, http.StatusConflict, "user.send_code.no_phone")
+	ErrSendVerifyCodeUnavailable = register(0x2, http.StatusServiceUnavailable, "user.send_code.unavailable")
+
+	// Verify
+	ErrVerifyInvalidParams = register(0x1, http.StatusBadRequest, "user.verify.invalid_params")
+	ErrVerifyWrongCode     = register(0x2, http.StatusBadRequest, "user.verify.wrong_code")
+
+	// EnrollTOTP
+	ErrEnrollTOTPEnabled = register(0x1, http.StatusConflict, "user.totp_enroll.enabled")
+
+	// ConfirmTOTP
+	ErrConfirmTOTPInvalidParams = register(0x1, http.StatusBadRequest, "user.totp_confirm.invalid_params")
+	ErrConfirmTOTPNotEnrolled   = register(0x2, http.StatusConflict, "user.totp_confirm.not_enrolled")
+	ErrConfirmTOTPWrongCode     = register(0x3, http.StatusBadRequest, "user.totp_confirm.wrong_code")
+
+	// RegenerateBackupCodes
+	ErrRegenerateBackupCodesNoTOTP = register(0x1, http.StatusConflict, "user.backup_codes.no_totp")
 )
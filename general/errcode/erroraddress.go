@@ -25,31 +25,50 @@
 /*
  * Revision History:
  *     Initial: 2017/05/14        Feng Yifei
+ *     Modify : 2026/10/19
  */
 
 package errcode
 
+import (
+	"net/http"
+)
+
 const (
 	// AddAddress
-	AddAddressSucceed          = 0x0
-	ErrAddAddressInvalidParams = 0x1
+	AddAddressSucceed = 0x0
+
+	// ChangeAddress
+	ChangeAddressSucceed = 0x0
+
+	// GetAddress
+	GetAddressSucceed = 0x0
+
+	// AlterDefault
+	AlterDefaultSucceed = 0x0
+
+	// DeleteAddress
+	DeleteAddressSucceed = 0x0
+)
+
+var (
+	// AddAddress
+	ErrAddAddressInvalidParams = register(0x1, http.StatusBadRequest, "address.add.invalid_params")
+	ErrAddAddressLimit         = register(0x2, http.StatusConflict, "address.add.limit")
 
 	// ChangeAddress
-	ChangeAddressSucceed          = 0x0
-	ErrChangeAddressInvalidParams = 0x1
-	ErrChangeAddressNotFound      = 0x2
+	ErrChangeAddressInvalidParams = register(0x1, http.StatusBadRequest, "address.change.invalid_params")
+	ErrChangeAddressNotFound      = register(0x2, http.StatusNotFound, "address.change.not_found")
 
 	// GetAddress
-	GetAddressSucceed     = 0x0
-	ErrGetAddressNotFound = 0x1
+	ErrGetAddressNotFound      = register(0x1, http.StatusNotFound, "address.get.not_found")
+	ErrGetAddressInvalidParams = register(0x2, http.StatusBadRequest, "address.get.invalid_params")
 
 	// AlterDefault
-	AlterDefaultSucceed          = 0x0
-	ErrAlterDefaultInvalidParams = 0x1
-	ErrAlterDefaultNotFound      = 0x2
+	ErrAlterDefaultInvalidParams = register(0x1, http.StatusBadRequest, "address.alter_default.invalid_params")
+	ErrAlterDefaultNotFound      = register(0x2, http.StatusNotFound, "address.alter_default.not_found")
 
 	// DeleteAddress
-	DeleteAddressSucceed          = 0x0
-	ErrDeleteAddressInvalidParams = 0x1
-	ErrDeleteAddressNotFound      = 0x2
+	ErrDeleteAddressInvalidParams = register(0x1, http.StatusBadRequest, "address.delete.invalid_params")
+	ErrDeleteAddressNotFound      = register(0x2, http.StatusNotFound, "address.delete.not_found")
 )
@@ -0,0 +1,221 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package errcode
+
+const (
+	LangZH = "zh-CN"
+	LangEN = "en"
+)
+
+type message struct {
+	zh string
+	en string
+}
+
+// messages is what clients are shown for each registered error, keyed by
+// Code.Key. Underlying error strings stay in the logs.
+var messages = map[string]message{
+	// General
+	"invalid_params":         {"请求参数错误", "Invalid request parameters"},
+	"duplicate":              {"数据已存在", "Already exists"},
+	"must_login":             {"请先登录", "Please log in first"},
+	"must_admin":             {"需要管理员权限", "Administrator access required"},
+	"must_verify":            {"请先完成身份验证", "Verification required"},
+	"database":               {"服务器繁忙，请稍后再试", "The server is busy, please try again later"},
+	"mongo":                  {"服务器繁忙，请稍后再试", "The server is busy, please try again later"},
+	"user_not_found":         {"用户不存在", "User not found"},
+	"invalid_password":       {"密码错误", "Wrong password"},
+	"not_found":              {"未找到相关数据", "Nothing was found"},
+	"bind":                   {"请求格式错误", "Malformed request"},
+	"information":            {"信息不完整", "Incomplete information"},
+	"address_id_not_found":   {"地址不存在", "Address not found"},
+	"internal":               {"服务器内部错误", "Internal server error"},
+	"route_not_found":        {"接口不存在", "No such API"},
+	"method_not_allowed":     {"请求方法不支持", "Method not allowed"},
+	"unsupported_media_type": {"不支持的请求格式", "Unsupported media type"},
+	"bad_request":            {"请求无效", "Bad request"},
+	"not_ready":              {"服务暂不可用", "Service unavailable"},
+	"too_many_requests":      {"请求过于频繁，请稍后再试", "Too many requests, please try again later"},
+
+	// Idempotency-Key
+	"idempotency.invalid_key": {"Idempotency-Key 无效", "Invalid Idempotency-Key"},
+	"idempotency.key_reused":  {"Idempotency-Key 已用于其他请求", "The Idempotency-Key was used for a different request"},
+	"idempotency.in_progress": {"相同请求正在处理中，请稍后重试", "The same request is still in progress, please retry later"},
+
+	// Order
+	"order.create.invalid_params":            {"下单参数错误", "Invalid order parameters"},
+	"order.create.address_not_found":         {"收货地址不存在", "Shipping address not found"},
+	"order.create.cart_not_found":            {"商品不在购物车中", "An ordered product isn't in the cart"},
+	"order.list.invalid_params":              {"订单查询参数错误", "Invalid order query"},
+	"order.list.invalid_status":              {"订单状态无效", "Invalid order status"},
+	"order.get.invalid_params":               {"订单编号错误", "Invalid order ID"},
+	"order.change_status.invalid_params":     {"订单状态参数错误", "Invalid order status change"},
+	"order.change_status.not_allowed":        {"订单当前状态不能进行此操作", "The order can't move to that status now"},
+	"order.change_address.invalid_params":    {"修改地址参数错误", "Invalid address change"},
+	"order.change_address.not_found":         {"订单不存在", "Order not found"},
+	"order.change_address.address_not_found": {"收货地址不存在", "Shipping address not found"},
+	"order.change_address.not_editable":      {"订单已支付或已完成，不能修改地址", "The order is paid or finished, its address can't change"},
+
+	// Cart
+	"cart.put_in.invalid_params":      {"加入购物车参数错误", "Invalid cart item"},
+	"cart.put_in.product_not_found":   {"商品不存在", "Product not found"},
+	"cart.put_in.database":            {"加入购物车失败，请稍后再试", "Failed to add to cart, please try again later"},
+	"cart.delete.invalid_params":      {"删除参数错误", "Invalid cart item"},
+	"cart.delete.product_not_found":   {"购物车中没有该商品", "The product is not in the cart"},
+	"cart.delete_many.invalid_params": {"删除参数错误", "Invalid cart items"},
+	"cart.alter.invalid_params":       {"修改参数错误", "Invalid cart item"},
+	"cart.alter.product_not_found":    {"购物车中没有该商品", "The product is not in the cart"},
+	"cart.browse.invalid_params":      {"购物车查询参数错误", "Invalid cart query"},
+	"cart.browse.not_found":           {"购物车为空", "The cart is empty"},
+
+	// Product
+	"product.create.invalid_params":          {"商品信息错误", "Invalid product"},
+	"product.create.database":                {"创建商品失败，请稍后再试", "Failed to create the product, please try again later"},
+	"product.list.database":                  {"获取商品列表失败，请稍后再试", "Failed to load products, please try again later"},
+	"product.by_category.invalid_params":     {"分类查询参数错误", "Invalid category query"},
+	"product.by_category.not_found":          {"该分类下没有商品", "No products in this category"},
+	"product.change_status.invalid_params":   {"商品状态参数错误", "Invalid product status"},
+	"product.info.invalid_params":            {"商品编号错误", "Invalid product ID"},
+	"product.info.not_found":                 {"商品不存在", "Product not found"},
+	"product.change_category.invalid_params": {"商品分类参数错误", "Invalid product category"},
+	"product.import.invalid_params":          {"导入文件错误", "Invalid import file"},
+	"product.import.too_large":               {"导入文件过大", "The import file is too large"},
+	"product.import_job.invalid_params":      {"导入任务编号错误", "Invalid import job ID"},
+	"product.import_job.not_found":           {"导入任务不存在", "Import job not found"},
+	"product.export.invalid_params":          {"导出格式错误", "Invalid export format"},
+
+	// Slot
+	"slot.create.invalid_params":    {"运营位参数错误", "Invalid slot"},
+	"region.children.not_found":     {"地区不存在", "Region not found"},
+	"slot.change.invalid_params":    {"运营位参数错误", "Invalid slot"},
+	"slot.change.not_found":         {"运营位不存在", "Slot not found"},
+	"slot.delete.invalid_params":    {"运营位编号错误", "Invalid slot ID"},
+	"slot.delete.not_found":         {"运营位不存在", "Slot not found"},
+	"slot.set_items.invalid_params": {"运营位内容参数错误", "Invalid slot items"},
+	"slot.set_items.not_found":      {"运营位不存在", "Slot not found"},
+
+	// User
+	"user.register.invalid_params":        {"注册信息错误", "Invalid registration"},
+	"user.register.duplicate":             {"用户已存在", "The user already exists"},
+	"user.login.invalid_params":           {"登录信息错误", "Invalid login"},
+	"user.login.user_not_found":           {"用户名或密码错误", "Wrong user name or password"},
+	"user.login.invalid_password":         {"用户名或密码错误", "Wrong user name or password"},
+	"user.login.locked":                   {"密码错误次数过多，账号已暂时锁定", "Too many failed logins, the account is locked for a while"},
+	"user.login.suspended":                {"账号已被停用", "The account is suspended"},
+	"user.logout.failed":                  {"退出登录失败，请稍后再试", "Failed to log out, please try again later"},
+	"user.get_info.invalid_params":        {"用户信息查询错误", "Invalid user query"},
+	"user.change_info.invalid_params":     {"用户信息错误", "Invalid user information"},
+	"user.change_avatar.invalid_params":   {"头像错误", "Invalid avatar"},
+	"user.change_phone.invalid_params":    {"手机号错误", "Invalid phone number"},
+	"user.change_phone.duplicate":         {"该手机号已被使用", "The phone number is already in use"},
+	"user.change_password.invalid_params": {"密码错误", "Wrong password"},
+	"user.delete.invalid_params":          {"密码错误", "Wrong password"},
+	"user.logout_session.invalid_params":  {"设备编号错误", "Invalid session ID"},
+	"user.logout_session.not_found":       {"该设备未登录", "No such session"},
+	"user.send_code.no_phone":             {"未绑定手机号", "No phone bound"},
+	"user.send_code.unavailable":          {"短信服务暂不可用，请使用其他验证方式", "SMS is unavailable, please use another verification method"},
+	"user.verify.invalid_params":          {"验证码错误", "Invalid verification code"},
+	"user.verify.wrong_code":              {"验证码错误或已失效", "Wrong or expired verification code"},
+	"user.totp_enroll.enabled":            {"已开启身份验证器", "Authenticator already enabled"},
+	"user.totp_confirm.invalid_params":    {"验证码错误", "Invalid verification code"},
+	"user.totp_confirm.not_enrolled":      {"请先添加身份验证器", "No authenticator to confirm"},
+	"user.totp_confirm.wrong_code":        {"验证码错误", "Wrong verification code"},
+	"user.backup_codes.no_totp":           {"未开启身份验证器", "Authenticator not enabled"},
+
+	// OAuth
+	"oauth.unknown_provider":              {"不支持该登录方式", "Unsupported login provider"},
+	"oauth.login.invalid_params":          {"登录信息错误", "Invalid login"},
+	"oauth.login.invalid_state":           {"登录已过期，请重新登录", "The login expired, please try again"},
+	"oauth.login.provider":                {"第三方登录失败，请稍后再试", "The login provider failed, please try again later"},
+	"oauth.bind_phone.invalid_params":     {"手机号或密码格式错误", "Invalid phone number or password"},
+	"oauth.bind_phone.already_bound":      {"账号已绑定手机号", "The account already has a phone number"},
+	"oauth.bind_phone.duplicate":          {"该手机号已被使用", "The phone number is already in use"},
+	"oauth.bind_phone.invalid_code":       {"验证码错误或已过期", "The code is wrong or expired"},
+	"oauth.send_bind_code.invalid_params": {"手机号格式错误", "Invalid phone number"},
+	"oauth.send_bind_code.unavailable":    {"短信服务暂不可用，请稍后再试", "SMS is unavailable, please try again later"},
+	"oauth.unbind_phone.not_bound":        {"账号未绑定手机号", "The account has no phone number"},
+	"oauth.unbind_phone.last_sign_in":     {"未绑定第三方账号，不能解绑手机号", "Link a login provider before removing the phone number"},
+
+	// Admin
+	"admin.login.invalid_params":           {"登录信息错误", "Invalid login"},
+	"admin.login.failed":                   {"用户名或密码错误", "Wrong user name or password"},
+	"admin.logout.failed":                  {"退出登录失败，请稍后再试", "Failed to log out, please try again later"},
+	"admin.suspend_user.invalid_params":    {"用户编号错误", "Invalid user ID"},
+	"admin.suspend_user.not_found":         {"用户不存在", "User not found"},
+	"admin.reactivate_user.invalid_params": {"用户编号错误", "Invalid user ID"},
+	"admin.reactivate_user.not_found":      {"用户不存在", "User not found"},
+	"admin.list_orders.invalid_params":     {"订单查询参数错误", "Invalid order query"},
+	"admin.get_order.invalid_params":       {"订单编号错误", "Invalid order ID"},
+	"admin.get_order.not_found":            {"订单不存在", "Order not found"},
+	"admin.add_order_note.invalid_params":  {"备注参数错误", "Invalid order note"},
+	"admin.add_order_note.not_found":       {"订单不存在", "Order not found"},
+	"admin.change_orders.invalid_params":   {"订单状态参数错误", "Invalid order status change"},
+	"admin.export_orders.invalid_params":   {"订单导出参数错误", "Invalid order export query"},
+
+	// Address
+	"address.add.invalid_params":           {"地址信息错误", "Invalid address"},
+	"address.add.limit":                    {"收货地址数量已达上限", "Address limit reached"},
+	"address.change.invalid_params":        {"地址信息错误", "Invalid address"},
+	"address.change.not_found":             {"地址不存在", "Address not found"},
+	"address.get.not_found":                {"还没有收货地址", "No address yet"},
+	"address.get.invalid_params":           {"地址查询参数错误", "Invalid address query"},
+	"address.alter_default.invalid_params": {"地址编号错误", "Invalid address ID"},
+	"address.alter_default.not_found":      {"地址不存在", "Address not found"},
+	"address.delete.invalid_params":        {"地址编号错误", "Invalid address ID"},
+	"address.delete.not_found":             {"地址不存在", "Address not found"},
+
+	// Report
+	"report.sales.invalid_params":          {"报表查询参数错误", "Invalid report query"},
+	"report.top_products.invalid_params":   {"报表查询参数错误", "Invalid report query"},
+	"report.top_categories.invalid_params": {"报表查询参数错误", "Invalid report query"},
+}
+
+// Message returns the text shown to clients in lang, English for LangEN and
+// Chinese otherwise.
+func (c *Code) Message(lang string) string {
+	m, ok := messages[c.Key]
+	if !ok {
+		m = messages[fallbackKey(c.Status)]
+	}
+
+	if lang == LangEN {
+		return m.en
+	}
+
+	return m.zh
+}
+
+func fallbackKey(status int) string {
+	if status >= 500 {
+		return "internal"
+	}
+
+	return "bad_request"
+}
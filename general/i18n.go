@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package general
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/general/errcode"
+)
+
+// Language picks the catalogue language from Accept-Language, Chinese when
+// the client accepts neither.
+func Language(c echo.Context) string {
+	type accepted struct {
+		tag string
+		q   float64
+	}
+
+	var langs []accepted
+
+	for _, part := range strings.Split(c.Request().Header.Get("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+
+		lang := accepted{tag: strings.ToLower(fields[0]), q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					lang.q = q
+				}
+			}
+		}
+
+		if lang.tag != "" && lang.q > 0 {
+			langs = append(langs, lang)
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	for _, lang := range langs {
+		switch {
+		case lang.tag == "zh" || strings.HasPrefix(lang.tag, "zh-"):
+			return errcode.LangZH
+		case lang.tag == "en" || strings.HasPrefix(lang.tag, "en-"):
+			return errcode.LangEN
+		}
+	}
+
+	return errcode.LangZH
+}
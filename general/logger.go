@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package general
+
+import (
+	"time"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/log"
+)
+
+const (
+	// Keys of values stored in echo.Context
+	ContextLogger  = "logger"
+	ContextUserID  = "userid"
+	ContextAdminID = "adminid"
+)
+
+// RequestLogger gives every request a logger carrying its ID, method and
+// route, and writes one access entry with status, user and latency when the
+// request ends.
+func RequestLogger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			logger := log.Logger.With(
+				"requestId", c.Response().Header().Get(echo.HeaderXRequestID),
+				"method", req.Method,
+				"route", c.Path(),
+			)
+			c.Set(ContextLogger, logger)
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			fields := []interface{}{
+				"status", c.Response().Status,
+				"latency", time.Since(start),
+				"remoteIp", c.RealIP(),
+			}
+
+			if userID := c.Get(ContextUserID); userID != nil {
+				fields = append(fields, "userId", userID)
+			}
+
+			if adminID := c.Get(ContextAdminID); adminID != nil {
+				fields = append(fields, "adminId", adminID)
+			}
+
+			logger.Infow("request", fields...)
+
+			return nil
+		}
+	}
+}
+
+// RequestLog returns the logger of the request, the global one outside of
+// RequestLogger.
+func RequestLog(c echo.Context) *log.RecordLog {
+	if logger, ok := c.Get(ContextLogger).(*log.RecordLog); ok {
+		return logger
+	}
+
+	return log.Logger
+}
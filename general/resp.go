@@ -25,6 +25,7 @@
 /*
  * Revision History:
  *     Initial: 2017/07/18        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package general
@@ -33,9 +34,19 @@ import (
 	"ShopApi/general/errcode"
 )
 
+// ErrorResp is the body of every failed request. Code is the business code,
+// Key the stable name of the error and the HTTP status comes from the
+// registered errcode. Message is replaced by the catalogue text before the
+// response is written, see EchoRestfulErrorHandler.
 type ErrorResp struct {
-	Code    int    `json:"status"`
-	Message string `json:"message"`
+	Code      int    `json:"status"`
+	Key       string `json:"error"`
+	Message   string `json:"message"`
+	Detail    string `json:"detail,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+
+	code   *errcode.Code
+	status int
 }
 
 type Resp struct {
@@ -43,8 +54,16 @@ type Resp struct {
 }
 
 type DataResp struct {
-	Code int         `json:"status"`
-	Data interface{} `json:"data"`
+	Code      int         `json:"status"`
+	Data      interface{} `json:"data"`
+	*PageInfo `json:",omitempty"`
+}
+
+// PageInfo is added to list responses.
+type PageInfo struct {
+	Total      uint64 `json:"total"`
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 type ProductListResp struct {
@@ -55,16 +74,16 @@ type ProductListResp struct {
 type ProductList struct {
 	Header interface{} `json:"adPics"`
 	Image  interface{} `json:"wares"`
+	Tiles  interface{} `json:"tiles,omitempty"`
 }
 
-func NewErrorWithMessage(code int, msg string) *ErrorResp {
-	if code == errcode.ErrSucceed {
-		msg = ""
-	}
-
+func NewErrorWithMessage(code *errcode.Code, msg string) *ErrorResp {
 	return &ErrorResp{
-		Code:    code,
+		Code:    code.Value,
+		Key:     code.Key,
 		Message: msg,
+		code:    code,
+		status:  code.Status,
 	}
 }
 
@@ -72,6 +91,11 @@ func (this *ErrorResp) Error() string {
 	return this.Message
 }
 
+// Status is the HTTP status the error is answered with.
+func (this *ErrorResp) Status() int {
+	return this.status
+}
+
 func NewMessage(code int) *Resp {
 	return &Resp{
 		Code: code,
@@ -85,12 +109,21 @@ func NewMessageWithData(code int, data interface{}) *DataResp {
 	}
 }
 
-func NewMessageForProductList(code int, header, image interface{}) *ProductListResp {
+func NewMessageWithPage(code int, data interface{}, page *PageInfo) *DataResp {
+	return &DataResp{
+		Code:     code,
+		Data:     data,
+		PageInfo: page,
+	}
+}
+
+func NewMessageForProductList(code int, header, image, tiles interface{}) *ProductListResp {
 	return &ProductListResp{
 		Code: code,
 		ProductList: ProductList{
 			Header: header,
 			Image:  image,
+			Tiles:  tiles,
 		},
 	}
 }
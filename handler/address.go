@@ -28,12 +28,15 @@
  *     Modify : 2017/07/20       Yu Yi
  *     Modify : 2017/07/20       Yang Zhengtian
  *     Modify : 2017/07/27       Li Zebang
+ *     Modify : 2026/10/19
  */
 
 package handler
 
 import (
 	"errors"
+	"net/http"
+	"strconv"
 
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
@@ -63,19 +66,49 @@ func AddAddress(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrAddAddressInvalidParams, err.Error())
 	}
 
+	if err = addAddress.ResolveRegion(); err != nil {
+		log.Logger.Error("[ERROR] AddAddress ResolveRegion:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAddAddressInvalidParams, err.Error())
+	}
+
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	addAddress.ID = 0
 	addAddress.UserID = session.Get(general.SessionUserID).(uint64)
 
-	err = models.AddressService.AddAddress(&addAddress)
+	if addAddress.IsDefault && currentStepUpPolicy().DefaultAddress {
+		verified, err := verifiedRecently(session, addAddress.UserID)
+		if err != nil {
+			log.Logger.Error("[ERROR] AddAddress verifiedRecently: Mysql Error", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+		}
+
+		if !verified {
+			err = errors.New("Verification Required.")
+
+			log.Logger.Error("[ERROR] AddAddress:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMustVerify, err.Error())
+		}
+	}
+
+	id, err := models.AddressService.AddAddress(&addAddress)
 	if err != nil {
+		if err == models.ErrAddressLimit {
+			log.Logger.Error("[ERROR] AddAddress AddAddress: Limit Reached", err)
+
+			return general.NewErrorWithMessage(errcode.ErrAddAddressLimit, err.Error())
+		}
+
 		log.Logger.Error("[ERROR] AddAddress AddAddress:", err)
 
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	log.Logger.Info("[SUCCEED] AddAddress: UserID %d", addAddress.UserID)
+	log.Logger.Info("[SUCCEED] AddAddress: UserID %d Address %d", addAddress.UserID, id)
 
-	return c.JSON(errcode.ErrSucceed, general.NewMessage(errcode.AddAddressSucceed))
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.AddAddressSucceed, map[string]interface{}{"id": strconv.FormatUint(id, 10)}))
 }
 
 func ChangeAddress(c echo.Context) error {
@@ -96,6 +129,20 @@ func ChangeAddress(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrChangeAddressInvalidParams, err.Error())
 	}
 
+	if changeAddress.ID == 0 {
+		err = errors.New("Address ID is required.")
+
+		log.Logger.Error("[ERROR] ChangeAddress:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeAddressInvalidParams, err.Error())
+	}
+
+	if err = changeAddress.ResolveRegion(); err != nil {
+		log.Logger.Error("[ERROR] ChangeAddress ResolveRegion:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeAddressInvalidParams, err.Error())
+	}
+
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	userID := session.Get(general.SessionUserID).(uint64)
 
@@ -114,6 +161,12 @@ func ChangeAddress(c echo.Context) error {
 
 	err = models.AddressService.ChangeAddress(&changeAddress, userID)
 	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] ChangeAddress ChangeAddress: Not Found", err)
+
+			return general.NewErrorWithMessage(errcode.ErrChangeAddressNotFound, err.Error())
+		}
+
 		log.Logger.Error("[ERROR] ChangeAddress ChangeAddress:", err)
 
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
@@ -121,20 +174,34 @@ func ChangeAddress(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] ChangeAddress: UserID %d", userID)
 
-	return c.JSON(errcode.ChangeAddressSucceed, general.NewMessage(errcode.ChangeAddressSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeAddressSucceed))
 }
 
 func GetAddress(c echo.Context) error {
 	var (
 		err         error
 		userID      uint64
+		pagination  utility.Pagination
 		addressList *[]models.AddressJSON
+		page        *general.PageInfo
 	)
 
+	if err = c.Bind(&pagination); err != nil {
+		log.Logger.Error("[ERROR] GetAddress Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetAddressInvalidParams, err.Error())
+	}
+
+	if err = pagination.Normalize(); err != nil {
+		log.Logger.Error("[ERROR] GetAddress Normalize:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetAddressInvalidParams, err.Error())
+	}
+
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	userID = session.Get(general.SessionUserID).(uint64)
 
-	addressList, err = models.AddressService.GetAddressByUserID(userID)
+	addressList, page, err = models.AddressService.GetAddressByUserID(userID, &pagination)
 	if err != nil {
 		log.Logger.Error("[ERROR] GetAddress GetAddressByUserID ", err)
 
@@ -151,7 +218,7 @@ func GetAddress(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] GetAddress: UserID %d", userID)
 
-	return c.JSON(errcode.GetAddressSucceed, general.NewMessageWithData(errcode.GetAddressSucceed, *addressList))
+	return c.JSON(http.StatusOK, general.NewMessageWithPage(errcode.GetAddressSucceed, *addressList, page))
 }
 
 func AlterDefault(c echo.Context) error {
@@ -191,6 +258,12 @@ func AlterDefault(c echo.Context) error {
 
 	err = models.AddressService.AlterAddress(alterAddress, userID)
 	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] AlterDefault AlterAddress: Not Found", err)
+
+			return general.NewErrorWithMessage(errcode.ErrAlterDefaultNotFound, err.Error())
+		}
+
 		log.Logger.Error("[ERROR] AlterDefault AlterAddress:", err)
 
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
@@ -198,7 +271,7 @@ func AlterDefault(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] AlterDefault: UserID %d", userID)
 
-	return c.JSON(errcode.AlterDefaultSucceed, general.NewMessage(errcode.AlterDefaultSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.AlterDefaultSucceed))
 }
 
 func DeleteAddress(c echo.Context) error {
@@ -223,25 +296,20 @@ func DeleteAddress(c echo.Context) error {
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	userID = session.Get(general.SessionUserID).(uint64)
 
-	err = models.AddressService.FindAddress(deleteAddress.ID, userID)
+	err = models.AddressService.DeleteAddress(deleteAddress, userID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			log.Logger.Error("[ERROR] DeleteAddress FindAddressByAddressID: Not Found", err)
+			log.Logger.Error("[ERROR] DeleteAddress DeleteAddress: Not Found", err)
 
 			return general.NewErrorWithMessage(errcode.ErrDeleteAddressNotFound, err.Error())
 		}
 
-		log.Logger.Error("[ERROR] DeleteAddress FindAddressByAddressID: MySQL ERROR", err)
+		log.Logger.Error("[ERROR] DeleteAddress DeleteAddress: MySQL ERROR", err)
 
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	err = models.AddressService.DeleteAddress(deleteAddress)
-	if err != nil {
-		log.Logger.Debug(err.Error())
-	}
-
 	log.Logger.Info("[SUCCEED] DeleteAddress: UserID %d", userID)
 
-	return c.JSON(errcode.DeleteAddressSucceed, general.NewMessage(errcode.DeleteAddressSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.DeleteAddressSucceed))
 }
@@ -0,0 +1,186 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/models"
+	"ShopApi/utility"
+)
+
+func AdminLogin(c echo.Context) error {
+	var (
+		err   error
+		login models.AdminLogin
+	)
+
+	if err = c.Bind(&login); err != nil {
+		log.Logger.Error("[ERROR] AdminLogin Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminLoginInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(login); err != nil {
+		log.Logger.Error("[ERROR] AdminLogin Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminLoginInvalidParams, err.Error())
+	}
+
+	flag, adminID, err := models.AdminService.Login(login.Username, login.Pass)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Logger.Error("[ERROR] AdminLogin Login: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if !flag {
+		err = errors.New("Admin username and password not match.")
+
+		log.Logger.Error("[ERROR] AdminLogin Login:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminLoginFailed, err.Error())
+	}
+
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	session.Set(general.SessionAdminID, adminID)
+
+	log.Logger.Info("[SUCCEED] AdminLogin: Admin ID %d", adminID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.AdminLoginSucceed))
+}
+
+func AdminLogout(c echo.Context) error {
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	adminID := session.Get(general.SessionAdminID)
+
+	err := session.Delete(general.SessionAdminID)
+	if err != nil {
+		log.Logger.Error("[ERROR] AdminLogout:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminLogout, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] AdminLogout: Admin ID %d", adminID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.AdminLogoutSucceed))
+}
+
+// SuspendUser stops a user from logging in and ends their sessions.
+func SuspendUser(c echo.Context) error {
+	var (
+		err    error
+		change models.UserStatusChange
+	)
+
+	if err = c.Bind(&change); err != nil {
+		log.Logger.Error("[ERROR] SuspendUser Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSuspendUserInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(change); err != nil {
+		log.Logger.Error("[ERROR] SuspendUser Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSuspendUserInvalidParams, err.Error())
+	}
+
+	found, err := models.UserService.SetStatus(change.UserID, general.UserInactive)
+	if err != nil {
+		log.Logger.Error("[ERROR] SuspendUser SetStatus: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if !found {
+		err = errors.New("User doesn't exist.")
+
+		log.Logger.Error("[ERROR] SuspendUser SetStatus:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSuspendUserNotFound, err.Error())
+	}
+
+	err = models.SessionService.RevokeAll(change.UserID)
+	if err != nil {
+		log.Logger.Error("[ERROR] SuspendUser RevokeAll:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrInternal, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] SuspendUser: User ID %d by Admin ID %v", change.UserID, c.Get(general.ContextAdminID))
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.SuspendUserSucceed))
+}
+
+// ReactivateUser lifts a suspension or a login lockout.
+func ReactivateUser(c echo.Context) error {
+	var (
+		err    error
+		change models.UserStatusChange
+	)
+
+	if err = c.Bind(&change); err != nil {
+		log.Logger.Error("[ERROR] ReactivateUser Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrReactivateUserInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(change); err != nil {
+		log.Logger.Error("[ERROR] ReactivateUser Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrReactivateUserInvalidParams, err.Error())
+	}
+
+	found, err := models.UserService.SetStatus(change.UserID, general.UserActive)
+	if err != nil {
+		log.Logger.Error("[ERROR] ReactivateUser SetStatus: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if !found {
+		err = errors.New("User doesn't exist.")
+
+		log.Logger.Error("[ERROR] ReactivateUser SetStatus:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrReactivateUserNotFound, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] ReactivateUser: User ID %d by Admin ID %v", change.UserID, c.Get(general.ContextAdminID))
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ReactivateUserSucceed))
+}
@@ -0,0 +1,336 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/metrics"
+	"ShopApi/models"
+	"ShopApi/spreadsheet"
+)
+
+// exportHeader is the first row of an order export.
+var exportHeader = []string{
+	"orderno", "orderid", "userid", "status", "created", "paid", "shipped",
+	"receiver", "phone", "area", "address", "items", "totalprice", "freight",
+	"carrier", "trackingno", "remark",
+}
+
+// AdminListOrders searches the orders of all users.
+func AdminListOrders(c echo.Context) error {
+	var (
+		err    error
+		query  models.AdminOrderQuery
+		orders []models.AdminOrderDetail
+		page   *general.PageInfo
+	)
+
+	if err = bindOrderQuery(c, &query); err != nil {
+		log.Logger.Error("[ERROR] AdminListOrders Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminListOrdersInvalidParams, err.Error())
+	}
+
+	if err = query.Normalize(); err != nil {
+		log.Logger.Error("[ERROR] AdminListOrders Normalize:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminListOrdersInvalidParams, err.Error())
+	}
+
+	orders, page, err = models.OrderService.AdminOrders(&query)
+	if err != nil {
+		log.Logger.Error("[ERROR] AdminListOrders: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithPage(errcode.AdminListOrdersSucceed, orders, page))
+}
+
+// AdminGetOrder returns any order with its history and internal notes.
+func AdminGetOrder(c echo.Context) error {
+	var (
+		err   error
+		ref   models.OrderRef
+		order *models.AdminOrderDetail
+	)
+
+	if err = c.Bind(&ref); err != nil {
+		log.Logger.Error("[ERROR] AdminGetOrder Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminGetOrderInvalidParams, err.Error())
+	}
+
+	if err = ref.Validate(); err != nil {
+		log.Logger.Error("[ERROR] AdminGetOrder Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminGetOrderInvalidParams, err.Error())
+	}
+
+	order, err = models.OrderService.AdminOrder(&ref)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] AdminGetOrder: Order doesn't exist", err)
+
+			return general.NewErrorWithMessage(errcode.ErrAdminGetOrderNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] AdminGetOrder: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.AdminGetOrderSucceed, order))
+}
+
+// AddOrderNote leaves an internal note on an order.
+func AddOrderNote(c echo.Context) error {
+	var (
+		err  error
+		add  models.AddOrderNote
+		note *models.OrderNote
+	)
+
+	if err = c.Bind(&add); err != nil {
+		log.Logger.Error("[ERROR] AddOrderNote Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAddOrderNoteInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(add); err != nil {
+		log.Logger.Error("[ERROR] AddOrderNote Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAddOrderNoteInvalidParams, err.Error())
+	}
+
+	if err = add.OrderRef.Validate(); err != nil {
+		log.Logger.Error("[ERROR] AddOrderNote Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAddOrderNoteInvalidParams, err.Error())
+	}
+
+	adminID := c.Get(general.ContextAdminID).(uint64)
+
+	note, err = models.OrderService.AddNote(adminID, &add)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] AddOrderNote: Order doesn't exist", err)
+
+			return general.NewErrorWithMessage(errcode.ErrAddOrderNoteNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] AddOrderNote: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] AddOrderNote: Order ID %d by Admin ID %d", note.OrderID, adminID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.AddOrderNoteSucceed, note))
+}
+
+// AdminChangeOrders moves several orders to one status, such as marking a
+// batch shipped. Orders which can't move are reported without failing the
+// others.
+func AdminChangeOrders(c echo.Context) error {
+	var (
+		err    error
+		change models.AdminChangeStatus
+	)
+
+	if err = c.Bind(&change); err != nil {
+		log.Logger.Error("[ERROR] AdminChangeOrders Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminChangeOrdersInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(change); err != nil {
+		log.Logger.Error("[ERROR] AdminChangeOrders Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminChangeOrdersInvalidParams, err.Error())
+	}
+
+	if !models.ValidOrderStatus(change.Status) {
+		err = errors.New("Invalid Order Status")
+
+		log.Logger.Error("[ERROR] AdminChangeOrders:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrAdminChangeOrdersInvalidParams, err.Error())
+	}
+
+	for i := range change.Orders {
+		if err = change.Orders[i].Validate(); err != nil {
+			log.Logger.Error("[ERROR] AdminChangeOrders Validate:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrAdminChangeOrdersInvalidParams, err.Error())
+		}
+	}
+
+	results := models.OrderService.AdminChangeStatus(&change)
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	if change.Status == general.OrderSettled {
+		metrics.Payments.Add(float64(len(results) - failed))
+	}
+
+	log.Logger.Info("[SUCCEED] AdminChangeOrders: %d orders to status %d, %d failed, by Admin ID %v",
+		len(results), change.Status, failed, c.Get(general.ContextAdminID))
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.AdminChangeOrdersSucceed, results))
+}
+
+// ExportOrders writes every order matching the query as CSV. The file
+// starts with a byte order mark so spreadsheet programs read it as UTF-8.
+func ExportOrders(c echo.Context) error {
+	var (
+		err   error
+		query models.AdminOrderQuery
+	)
+
+	if err = bindOrderQuery(c, &query); err != nil {
+		log.Logger.Error("[ERROR] ExportOrders Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrExportOrdersInvalidParams, err.Error())
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	resp.Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"orders-%s.csv\"", time.Now().Format("20060102150405")))
+	resp.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(resp)
+	resp.Write([]byte("\xef\xbb\xbf"))
+	w.Write(exportHeader)
+
+	rows := 0
+	err = models.OrderService.ExportOrders(&query, func(orders []models.AdminOrderDetail) error {
+		for i := range orders {
+			if err := w.Write(exportRow(&orders[i])); err != nil {
+				return err
+			}
+		}
+
+		rows += len(orders)
+		w.Flush()
+
+		return w.Error()
+	})
+
+	// The status line is already sent, a failure can only cut the file short.
+	if err != nil {
+		log.Logger.Error("[ERROR] ExportOrders:", fmt.Errorf("cut short after %d orders: %v", rows, err))
+
+		return nil
+	}
+
+	w.Flush()
+
+	log.Logger.Info("[SUCCEED] ExportOrders: %d orders by Admin ID %v", rows, c.Get(general.ContextAdminID))
+
+	return nil
+}
+
+func bindOrderQuery(c echo.Context, query *models.AdminOrderQuery) error {
+	if err := c.Bind(query); err != nil {
+		return err
+	}
+
+	if err := c.Validate(query); err != nil {
+		return err
+	}
+
+	return query.Validate()
+}
+
+func exportRow(o *models.AdminOrderDetail) []string {
+	items := make([]string, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = fmt.Sprintf("%s %s/%s x%d", item.Name, item.Size, item.Color, item.Count)
+	}
+
+	row := []string{
+		o.OrderNo,
+		strconv.FormatUint(o.ID, 10),
+		strconv.FormatUint(o.UserID, 10),
+		o.StatusName,
+		o.Created.Format(time.RFC3339),
+		exportTime(o.Payment.Paid),
+		"",
+		"", "", "", "",
+		strings.Join(items, "; "),
+		strconv.FormatFloat(o.TotalPrice, 'f', 2, 64),
+		strconv.FormatFloat(o.Freight, 'f', 2, 64),
+		"", "",
+		o.Remark,
+	}
+
+	if o.Address != nil {
+		row[7], row[8], row[9], row[10] = o.Address.Receiver, o.Address.Phone, o.Address.Area, o.Address.Address
+	}
+
+	if o.Shipment != nil {
+		row[6], row[14], row[15] = exportTime(o.Shipment.Shipped), o.Shipment.Carrier, o.Shipment.TrackingNo
+	}
+
+	// Remarks, names and addresses come from customers.
+	for i := range row {
+		row[i] = spreadsheet.QuoteFormula(row[i])
+	}
+
+	return row
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
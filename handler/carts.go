@@ -36,6 +36,7 @@ package handler
 
 import (
 	"io"
+	"net/http"
 
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
@@ -98,7 +99,7 @@ func CreateCarts(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] CartsPutIn name:%s", ProInfo.Name)
 
-	return c.JSON(errcode.CreateSucceed, general.NewMessage(errcode.CreateSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.CreateSucceed))
 }
 
 func CartsDelete(c echo.Context) error {
@@ -131,7 +132,7 @@ func CartsDelete(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] CartsDelete: UserID %d", userID)
 
-	return c.JSON(errcode.CartsDeleteSucceed, general.NewMessage(errcode.CartsDeleteSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.CartsDeleteSucceed))
 }
 
 func AlterCartPro(c echo.Context) error {
@@ -167,19 +168,33 @@ func AlterCartPro(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] AlterCartPro %v")
 
-	return c.JSON(errcode.AlterCartSucceed, general.NewMessage(errcode.AlterCartSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.AlterCartSucceed))
 }
 
 func CartsBrowse(c echo.Context) error {
 	var (
-		err    error
-		output *[]models.ConCarts
+		err        error
+		pagination utility.Pagination
+		output     *[]models.ConCarts
+		page       *general.PageInfo
 	)
 
+	if err = c.Bind(&pagination); err != nil {
+		log.Logger.Error("[ERROR] CartsBrowse Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrBrowseInvalidParams, err.Error())
+	}
+
+	if err = pagination.Normalize(); err != nil {
+		log.Logger.Error("[ERROR] CartsBrowse Normalize:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrBrowseInvalidParams, err.Error())
+	}
+
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	userID := session.Get(general.SessionUserID).(uint64)
 
-	output, err = models.CartsService.CartsBrowse(userID)
+	output, page, err = models.CartsService.CartsBrowse(userID, &pagination)
 	if err != nil {
 		log.Logger.Error("[ERROR] CartsBrowse", err)
 
@@ -188,5 +203,5 @@ func CartsBrowse(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] CartsBrowse %v")
 
-	return c.JSON(errcode.BrowseCartSucceed, general.NewMessageWithData(errcode.BrowseCartSucceed, output))
+	return c.JSON(http.StatusOK, general.NewMessageWithPage(errcode.BrowseCartSucceed, output, page))
 }
@@ -0,0 +1,244 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/models"
+	"ShopApi/spreadsheet"
+	"ShopApi/utility"
+)
+
+// maxImportSize is the largest import file accepted.
+const maxImportSize = 10 << 20
+
+// ImportProducts takes a CSV or XLSX file of products and imports it in the
+// background, the response carries the job to poll with GetImportJob.
+func ImportProducts(c echo.Context) error {
+	var err error
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		log.Logger.Error("[ERROR] ImportProducts FormFile:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrImportProductsInvalidParams, err.Error())
+	}
+
+	if file.Size > maxImportSize {
+		err = fmt.Errorf("file is larger than %d bytes", maxImportSize)
+
+		log.Logger.Error("[ERROR] ImportProducts:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrImportProductsTooLarge, err.Error())
+	}
+
+	format, err := spreadsheet.Format(file.Filename)
+	if err != nil {
+		log.Logger.Error("[ERROR] ImportProducts Format:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrImportProductsInvalidParams, err.Error())
+	}
+
+	dryRun := false
+	if v := c.FormValue("dryrun"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			log.Logger.Error("[ERROR] ImportProducts dryrun:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrImportProductsInvalidParams, err.Error())
+		}
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Logger.Error("[ERROR] ImportProducts Open:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrImportProductsInvalidParams, err.Error())
+	}
+	defer src.Close()
+
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		log.Logger.Error("[ERROR] ImportProducts ReadAll:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrImportProductsInvalidParams, err.Error())
+	}
+
+	// The header comes before the products.
+	rows, err := spreadsheet.Read(data, format, models.MaxImportRows+1)
+	if err != nil {
+		switch err {
+		case spreadsheet.ErrTooManyRows:
+			err = fmt.Errorf("file has more than %d products", models.MaxImportRows)
+
+			log.Logger.Error("[ERROR] ImportProducts:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrImportProductsTooLarge, err.Error())
+		case spreadsheet.ErrTooManyColumns, spreadsheet.ErrPartTooLarge:
+			log.Logger.Error("[ERROR] ImportProducts:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrImportProductsTooLarge, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] ImportProducts Read:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrImportProductsInvalidParams, err.Error())
+	}
+
+	adminID := c.Get(general.ContextAdminID).(uint64)
+
+	job, err := models.ProductService.CreateImportJob(adminID, file.Filename, dryRun)
+	if err != nil {
+		log.Logger.Error("[ERROR] ImportProducts CreateImportJob: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	utility.GoBackground(func(ctx context.Context) {
+		if err := models.ProductService.RunImport(job, rows); err != nil {
+			log.Logger.Error("[ERROR] ImportProducts RunImport:", err)
+			return
+		}
+
+		log.Logger.Info("[SUCCEED] ImportProducts: Job %d, %d rows, %d failed", job.ID, job.Total, job.Failed)
+	})
+
+	log.Logger.Info("[SUCCEED] ImportProducts: Job %d queued by Admin ID %d", job.ID, adminID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.ImportProductsSucceed, map[string]uint64{"jobid": job.ID}))
+}
+
+// GetImportJob returns the progress of an import and the rows it rejected.
+func GetImportJob(c echo.Context) error {
+	var (
+		err error
+		req models.ImportJobID
+		job *models.ImportJob
+	)
+
+	if err = c.Bind(&req); err != nil {
+		log.Logger.Error("[ERROR] GetImportJob Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetImportJobInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(req); err != nil {
+		log.Logger.Error("[ERROR] GetImportJob Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetImportJobInvalidParams, err.Error())
+	}
+
+	job, err = models.ProductService.ImportJob(req.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] GetImportJob: Job doesn't exist", err)
+
+			return general.NewErrorWithMessage(errcode.ErrGetImportJobNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] GetImportJob: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.GetImportJobSucceed, job))
+}
+
+// ExportProducts writes the whole catalog as CSV or XLSX in the columns
+// ImportProducts reads.
+func ExportProducts(c echo.Context) error {
+	var (
+		err    error
+		export models.CatalogExport
+	)
+
+	if err = c.Bind(&export); err != nil {
+		log.Logger.Error("[ERROR] ExportProducts Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrExportProductsInvalidParams, err.Error())
+	}
+
+	if export.Format == "" {
+		export.Format = spreadsheet.CSV
+	}
+
+	if export.Format != spreadsheet.CSV && export.Format != spreadsheet.XLSX {
+		err = spreadsheet.ErrUnknownFormat
+
+		log.Logger.Error("[ERROR] ExportProducts:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrExportProductsInvalidParams, err.Error())
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, spreadsheet.ContentType(export.Format))
+	resp.Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"products-%s.%s\"", time.Now().Format("20060102150405"), export.Format))
+	resp.WriteHeader(http.StatusOK)
+
+	// The status line is already sent, a failure can only cut the file short.
+	w, err := spreadsheet.NewWriter(resp, export.Format)
+	if err != nil {
+		log.Logger.Error("[ERROR] ExportProducts NewWriter:", err)
+
+		return nil
+	}
+
+	rows := -1
+	err = models.ProductService.ExportCatalog(func(row []string) error {
+		rows++
+
+		return w.Write(row)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err != nil {
+		log.Logger.Error("[ERROR] ExportProducts:", err)
+
+		return nil
+	}
+
+	log.Logger.Info("[SUCCEED] ExportProducts: %d products by Admin ID %v", rows, c.Get(general.ContextAdminID))
+
+	return nil
+}
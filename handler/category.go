@@ -33,6 +33,7 @@ package handler
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
@@ -41,6 +42,7 @@ import (
 	"ShopApi/general/errcode"
 	"ShopApi/log"
 	"ShopApi/models"
+	"ShopApi/utility"
 )
 
 func CreateCategory(c echo.Context) error {
@@ -82,23 +84,36 @@ func CreateCategory(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	return c.JSON(errcode.ErrSucceed, nil)
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ErrSucceed))
 }
 
 func GetCategory(c echo.Context) error {
 	var (
 		err          error
-		categoryList *[]models.CategoryGet
+		pagination   utility.Pagination
+		categoryList *models.CategoryPage
 	)
 
-	categoryList, err = models.CategoryService.GetCategory()
+	if err = c.Bind(&pagination); err != nil {
+		log.Logger.Error("[ERROR] GetCategory Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrInvalidParams, err.Error())
+	}
+
+	if err = pagination.Normalize(); err != nil {
+		log.Logger.Error("[ERROR] GetCategory Normalize:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrInvalidParams, err.Error())
+	}
+
+	categoryList, err = models.CategoryService.GetCategory(&pagination)
 	if err != nil {
 		log.Logger.Error("[ERROR] GetCategory GetCategory: MySQL ERROR", err)
 
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	if len(*categoryList) == 0 {
+	if len(categoryList.List) == 0 {
 		err = errors.New("[ERROR] Categories Not Found")
 
 		log.Logger.Error("[ERROR] GetCategory GetCategory: ", err)
@@ -106,5 +121,5 @@ func GetCategory(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrNotFound, err.Error())
 	}
 
-	return c.JSON(errcode.ErrSucceed, *categoryList)
+	return c.JSON(http.StatusOK, general.NewMessageWithPage(errcode.ErrSucceed, categoryList.List, categoryList.Page))
 }
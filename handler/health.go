@@ -0,0 +1,106 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/orm"
+)
+
+const pingTimeout = 2 * time.Second
+
+var draining int32
+
+// Drain makes Readyz fail so load balancers stop sending new requests while
+// the server shuts down.
+func Drain() {
+	atomic.StoreInt32(&draining, 1)
+}
+
+// Healthz answers as long as the process serves requests.
+func Healthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ErrSucceed))
+}
+
+// Readyz checks that MySQL and Mongo answer.
+func Readyz(c echo.Context) error {
+	if atomic.LoadInt32(&draining) == 1 {
+		err := errors.New("Server is shutting down")
+
+		return general.NewErrorWithMessage(errcode.ErrNotReady, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), pingTimeout)
+	defer cancel()
+
+	if err := orm.Conn.DB().PingContext(ctx); err != nil {
+		log.Logger.Error("[ERROR] Readyz MySQL:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrNotReady, err.Error())
+	}
+
+	if err := pingMongo(ctx); err != nil {
+		log.Logger.Error("[ERROR] Readyz Mongo:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrNotReady, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ErrSucceed))
+}
+
+// pingMongo gives up on Mongo when ctx is done, mgo has no context of its
+// own. The ping keeps its session until it returns.
+func pingMongo(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	go func() {
+		session := orm.MDSession.Copy()
+		defer session.Close()
+
+		done <- session.Ping()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
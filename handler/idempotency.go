@@ -0,0 +1,229 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/models"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+
+	maxIdempotencyKey = 64
+)
+
+// idempotencyWindow is how long a key replays its response and
+// idempotencyLease how long a request may hold a key before a retry claims
+// it again, both in nanoseconds.
+var (
+	idempotencyWindow = int64(24 * time.Hour)
+	idempotencyLease  = int64(time.Minute)
+)
+
+// SetIdempotencyWindow sets how long a key replays its first response.
+func SetIdempotencyWindow(window time.Duration) {
+	atomic.StoreInt64(&idempotencyWindow, int64(window))
+}
+
+// IdempotencyWindow returns the window set by SetIdempotencyWindow.
+func IdempotencyWindow() time.Duration {
+	return time.Duration(atomic.LoadInt64(&idempotencyWindow))
+}
+
+// SetIdempotencyLease sets how long a key stays claimed by a request which
+// hasn't finished.
+func SetIdempotencyLease(lease time.Duration) {
+	atomic.StoreInt64(&idempotencyLease, int64(lease))
+}
+
+// IdempotencyLease returns the lease set by SetIdempotencyLease.
+func IdempotencyLease() time.Duration {
+	return time.Duration(atomic.LoadInt64(&idempotencyLease))
+}
+
+// Idempotent lets clients retry a request sent with an Idempotency-Key
+// header safely. The first request runs and its response is stored, retries
+// within the window get the same response back, reusing the key for another
+// request fails. Keys are per user, it must run after MustLogin on routes
+// that need a login. Server errors are not stored so they can be retried.
+func Idempotent(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return next(c)
+		}
+
+		logger := general.RequestLog(c)
+
+		if len(key) > maxIdempotencyKey {
+			err := fmt.Errorf("Idempotency-Key longer than %d", maxIdempotencyKey)
+			logger.Error("[ERROR] Idempotent:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrIdempotencyInvalidKey, err.Error())
+		}
+
+		fingerprint, err := requestFingerprint(c)
+		if err != nil {
+			logger.Error("[ERROR] Idempotent read body:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrBadRequest, err.Error())
+		}
+
+		record, started, err := models.IdempotencyService.Begin(idempotencyScope(c), key, fingerprint, IdempotencyWindow(), IdempotencyLease())
+		if err != nil {
+			logger.Error("[ERROR] Idempotent Begin: Mysql Error", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+		}
+
+		if !started {
+			return replay(c, record, fingerprint)
+		}
+
+		w := &teeWriter{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = w
+
+		// A panic leaves nothing to store, give the key up so that the
+		// retry runs again.
+		defer func() {
+			if r := recover(); r != nil {
+				c.Response().Writer = w.ResponseWriter
+
+				if err := models.IdempotencyService.Release(record.ID); err != nil {
+					logger.Error("[ERROR] Idempotent release key: Mysql Error", err)
+				}
+
+				panic(r)
+			}
+		}()
+
+		if err = next(c); err != nil {
+			c.Error(err)
+		}
+
+		c.Response().Writer = w.ResponseWriter
+
+		status := c.Response().Status
+		if status >= http.StatusInternalServerError {
+			err = models.IdempotencyService.Release(record.ID)
+		} else {
+			err = models.IdempotencyService.Complete(record.ID, status, c.Response().Header().Get(echo.HeaderContentType), w.body.String())
+		}
+
+		if err != nil {
+			logger.Error("[ERROR] Idempotent save response: Mysql Error", err)
+		}
+
+		return nil
+	}
+}
+
+func replay(c echo.Context, record *models.Idempotency, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		err := errors.New("Idempotency-Key reused with a different request")
+		general.RequestLog(c).Error("[ERROR] Idempotent:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrIdempotencyKeyReused, err.Error())
+	}
+
+	if record.Status == 0 {
+		err := errors.New("Idempotency-Key still in progress")
+		general.RequestLog(c).Error("[ERROR] Idempotent:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrIdempotencyInProgress, err.Error())
+	}
+
+	c.Response().Header().Set(HeaderReplayed, "true")
+
+	return c.Blob(record.Status, record.ContentType, []byte(record.Body))
+}
+
+// requestFingerprint hashes the method, path and body, and leaves the body
+// for the handler.
+func requestFingerprint(c echo.Context) (string, error) {
+	req := c.Request()
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+
+		body, err = ioutil.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.Path)
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func idempotencyScope(c echo.Context) string {
+	if userID := c.Get(general.ContextUserID); userID != nil {
+		return fmt.Sprintf("user:%v", userID)
+	}
+
+	if adminID := c.Get(general.ContextAdminID); adminID != nil {
+		return fmt.Sprintf("admin:%v", adminID)
+	}
+
+	return "ip:" + c.RealIP()
+}
+
+// teeWriter keeps a copy of the response body.
+type teeWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
@@ -25,6 +25,7 @@
 /*
  * Revision History:
  *     Initial: 2017/07/20        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package handler
@@ -32,11 +33,13 @@ package handler
 import (
 	"errors"
 
+	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
 
 	"ShopApi/general"
 	"ShopApi/general/errcode"
 	"ShopApi/log"
+	"ShopApi/models"
 	"ShopApi/utility"
 )
 
@@ -52,6 +55,103 @@ func MustLogin(next echo.HandlerFunc) echo.HandlerFunc {
 			return general.NewErrorWithMessage(errcode.ErrMustLogin, err.Error())
 		}
 
+		loginAt, _ := sess.Get(general.SessionLoginAt).(int64)
+		if models.UserService.SessionRevoked(id.(uint64), loginAt) {
+			sess.Delete(general.SessionUserID)
+
+			err := errors.New("Session revoked.")
+
+			log.Logger.Error("[ERROR] MustLogin:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMustLogin, err.Error())
+		}
+
+		if deviceID, ok := sess.Get(general.SessionDeviceID).(uint64); ok {
+			active, err := models.SessionService.Active(deviceID)
+			if err != nil {
+				log.Logger.Error("[ERROR] MustLogin Active: Mysql Error", err)
+
+				return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+			}
+
+			if !active {
+				sess.Delete(general.SessionUserID)
+
+				err := errors.New("Device logged out.")
+
+				log.Logger.Error("[ERROR] MustLogin:", err)
+
+				return general.NewErrorWithMessage(errcode.ErrMustLogin, err.Error())
+			}
+
+			touchSession(sess, deviceID)
+		}
+
+		c.Set(general.ContextUserID, id)
+
+		return next(c)
+	}
+}
+
+// MustAdmin lets through admins logged in with AdminLogin whose account is
+// still active.
+func MustAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+		id, ok := sess.Get(general.SessionAdminID).(uint64)
+		if !ok {
+			err := errors.New("Admin Must Login.")
+
+			log.Logger.Error("[ERROR] MustAdmin:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMustAdmin, err.Error())
+		}
+
+		active, err := models.AdminService.IsActive(id)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] MustAdmin IsActive: Mysql Error", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+		}
+
+		if !active {
+			sess.Delete(general.SessionAdminID)
+
+			err = errors.New("Admin Inactive.")
+
+			log.Logger.Error("[ERROR] MustAdmin:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMustAdmin, err.Error())
+		}
+
+		c.Set(general.ContextAdminID, id)
+
+		return next(c)
+	}
+}
+
+// MustVerify lets through sessions which passed step-up verification
+// recently, it runs after MustLogin.
+func MustVerify(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+		userID := c.Get(general.ContextUserID).(uint64)
+
+		ok, err := verifiedRecently(sess, userID)
+		if err != nil {
+			log.Logger.Error("[ERROR] MustVerify: Mysql Error", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+		}
+
+		if !ok {
+			err = errors.New("Verification Required.")
+
+			log.Logger.Error("[ERROR] MustVerify:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrMustVerify, err.Error())
+		}
+
 		return next(c)
 	}
 }
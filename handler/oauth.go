@@ -0,0 +1,314 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/metrics"
+	"ShopApi/models"
+	"ShopApi/oauth"
+	"ShopApi/utility"
+)
+
+// oauthTimeout bounds the calls to the provider during a login.
+const oauthTimeout = 15 * time.Second
+
+type oauthCallback struct {
+	Code  string `json:"code" query:"code" validate:"required"`
+	State string `json:"state" query:"state" validate:"required"`
+}
+
+// OAuthAuthorize starts a login with the provider, the client opens the
+// returned URL and hands the code and state to OAuthLogin.
+func OAuthAuthorize(c echo.Context) error {
+	provider, err := oauth.Lookup(c.Param("provider"))
+	if err != nil {
+		log.Logger.Error("[ERROR] OAuthAuthorize Lookup:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthUnknownProvider, err.Error())
+	}
+
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		log.Logger.Error("[ERROR] OAuthAuthorize State:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrInternal, err.Error())
+	}
+	state := hex.EncodeToString(b)
+
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	session.Set(general.SessionOAuth, provider.Name()+":"+state)
+
+	data := map[string]string{
+		"url":   provider.AuthorizeURL(state),
+		"state": state,
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.OAuthAuthorizeSucceed, data))
+}
+
+// OAuthLogin finishes the login started by OAuthAuthorize, registering the
+// user on their first login. It accepts the code and state as JSON or as the
+// query of the provider's redirect.
+func OAuthLogin(c echo.Context) error {
+	var (
+		err      error
+		callback oauthCallback
+	)
+
+	provider, err := oauth.Lookup(c.Param("provider"))
+	if err != nil {
+		log.Logger.Error("[ERROR] OAuthLogin Lookup:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthUnknownProvider, err.Error())
+	}
+
+	if err = c.Bind(&callback); err != nil {
+		log.Logger.Error("[ERROR] OAuthLogin Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthLoginInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(callback); err != nil {
+		log.Logger.Error("[ERROR] OAuthLogin Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthLoginInvalidParams, err.Error())
+	}
+
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	expected, _ := session.Get(general.SessionOAuth).(string)
+	session.Delete(general.SessionOAuth)
+
+	if expected == "" || expected != provider.Name()+":"+callback.State {
+		err = errors.New("OAuth state doesn't match.")
+
+		log.Logger.Error("[ERROR] OAuthLogin:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthLoginInvalidState, err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), oauthTimeout)
+	defer cancel()
+
+	token, err := provider.Exchange(ctx, callback.Code)
+	if err != nil {
+		log.Logger.Error("[ERROR] OAuthLogin Exchange:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthLoginProvider, err.Error())
+	}
+
+	profile, err := provider.Profile(ctx, token)
+	if err != nil {
+		log.Logger.Error("[ERROR] OAuthLogin Profile:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrOAuthLoginProvider, err.Error())
+	}
+
+	result, err := models.IdentityService.SignIn(provider.Name(), profile.ID, profile.Nickname, profile.Avatar)
+	if err != nil {
+		if err == models.ErrUserSuspended {
+			log.Logger.Error("[ERROR] OAuthLogin SignIn: User suspended", err)
+
+			return general.NewErrorWithMessage(errcode.ErrLoginSuspended, err.Error())
+		}
+
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] OAuthLogin SignIn: User doesn't exist", err)
+
+			return general.NewErrorWithMessage(errcode.ErrLoginUserNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] OAuthLogin SignIn: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if err = startSession(c, result.UserID); err != nil {
+		log.Logger.Error("[ERROR] OAuthLogin startSession: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if result.Created {
+		metrics.Registrations.Inc()
+	}
+
+	log.Logger.Info("[SUCCEED] OAuthLogin: %s User ID %d", provider.Name(), result.UserID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.OAuthLoginSucceed, result))
+}
+
+// SendBindPhoneCode texts a code to the phone an account wants to bind, so
+// BindPhone can tell the phone belongs to the user.
+func SendBindPhoneCode(c echo.Context) error {
+	var (
+		err  error
+		send models.BindPhoneCode
+	)
+
+	if err = c.Bind(&send); err != nil {
+		log.Logger.Error("[ERROR] SendBindPhoneCode Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSendBindPhoneCodeInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(send); err != nil {
+		log.Logger.Error("[ERROR] SendBindPhoneCode Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSendBindPhoneCodeInvalidParams, err.Error())
+	}
+
+	if !utility.IsValidPhone(*send.Phone) {
+		err = errors.New("Invalid Phone.")
+
+		log.Logger.Error("[ERROR] SendBindPhoneCode IsValidPhone:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSendBindPhoneCodeInvalidParams, err.Error())
+	}
+
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	// Without a sender or a cache to keep the code, no code can be checked.
+	err = utility.CreateCode(*send.Phone)
+	if err != nil {
+		log.Logger.Error("[ERROR] SendBindPhoneCode CreateCode:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSendBindPhoneCodeUnavailable, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] SendBindPhoneCode: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.SendBindPhoneCodeSucceed))
+}
+
+// BindPhone adds a phone and password to an account created by OAuthLogin,
+// the code sent by SendBindPhoneCode proves the phone.
+func BindPhone(c echo.Context) error {
+	var (
+		err  error
+		bind models.BindPhone
+	)
+
+	if err = c.Bind(&bind); err != nil {
+		log.Logger.Error("[ERROR] BindPhone Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrBindPhoneInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(bind); err != nil {
+		log.Logger.Error("[ERROR] BindPhone Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrBindPhoneInvalidParams, err.Error())
+	}
+
+	if !utility.IsValidPhone(*bind.Phone) {
+		err = errors.New("Invalid Phone.")
+
+		log.Logger.Error("[ERROR] BindPhone IsValidPhone:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrBindPhoneInvalidParams, err.Error())
+	}
+
+	if !utility.VerifyCode(*bind.Phone, *bind.Code) {
+		err = errors.New("Invalid Code.")
+
+		log.Logger.Error("[ERROR] BindPhone VerifyCode:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrBindPhoneInvalidCode, err.Error())
+	}
+
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := session.Get(general.SessionUserID).(uint64)
+
+	err = models.IdentityService.BindPhone(userID, bind.Phone, bind.Password)
+	if err != nil {
+		if err == models.ErrPhoneAlreadyBound {
+			log.Logger.Error("[ERROR] BindPhone BindPhone:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrBindPhoneAlreadyBound, err.Error())
+		}
+
+		if strings.Contains(err.Error(), general.DuplicateEntry) {
+			log.Logger.Error("[ERROR] BindPhone BindPhone: Phone Duplicate", err)
+
+			return general.NewErrorWithMessage(errcode.ErrBindPhoneDuplicate, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] BindPhone BindPhone: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] BindPhone: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.BindPhoneSucceed))
+}
+
+// UnbindPhone removes the phone and password of an account which can still
+// log in with OAuth.
+func UnbindPhone(c echo.Context) error {
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := session.Get(general.SessionUserID).(uint64)
+
+	err := models.IdentityService.UnbindPhone(userID)
+	if err != nil {
+		switch err {
+		case models.ErrNoPhoneBound:
+			log.Logger.Error("[ERROR] UnbindPhone UnbindPhone:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrUnbindPhoneNotBound, err.Error())
+		case models.ErrLastSignIn:
+			log.Logger.Error("[ERROR] UnbindPhone UnbindPhone:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrUnbindPhoneLastSignIn, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] UnbindPhone UnbindPhone: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] UnbindPhone: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.UnbindPhoneSucceed))
+}
@@ -28,18 +28,21 @@
  *     Modify : 2017/07/21       Zhang Zizhao
  *	   Modify : 2017/07/21       Ai Hao
  *     Modify : 2017/07/21       Ma Chao
+ *     Modify : 2026/10/19
  */
 
 package handler
 
 import (
 	"errors"
+	"net/http"
 
 	"github.com/labstack/echo"
 
 	"ShopApi/general"
 	"ShopApi/general/errcode"
 	"ShopApi/log"
+	"ShopApi/metrics"
 	"ShopApi/models"
 	"ShopApi/utility"
 	"github.com/jinzhu/gorm"
@@ -66,25 +69,17 @@ func CreateOrder(c echo.Context) error {
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	UserID := session.Get(general.SessionUserID).(uint64)
 
-	err = models.OrderService.CreateOrder(UserID, order)
+	created, bought, err := models.OrderService.CreateOrder(UserID, order)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		switch err {
+		case models.ErrOrderAddressNotFound:
 			log.Logger.Error("[ERROR] CreateOrder: Address doesn't exist", err)
 
 			return general.NewErrorWithMessage(errcode.ErrAddressNotFound, err.Error())
-		}
-
-		log.Logger.Error("[ERROR] Mysql error:", err)
-
-		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
-	}
-
-	err = models.CartsService.CartsDelete(&models.CartsDeleted, UserID)
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		case models.ErrOrderCartNotFound:
 			log.Logger.Error("[ERROR] CreateOrder: Carts doesn't exist", err)
 
-			return general.NewErrorWithMessage(errcode.ErrAddressNotFound, err.Error())
+			return general.NewErrorWithMessage(errcode.ErrOrderCartNotFound, err.Error())
 		}
 
 		log.Logger.Error("[ERROR] Mysql error:", err)
@@ -92,17 +87,19 @@ func CreateOrder(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	log.Logger.Info("[SUCCEED] CartsDelete %v")
-	log.Logger.Info("[SUCCEED] CreateOrder %v")
+	log.Logger.Info("[SUCCEED] CartsOrdered %v", bought.Data)
+	log.Logger.Info("[SUCCEED] CreateOrder %d %s", created.ID, created.OrderNo)
+	metrics.OrdersCreated.Inc()
 
-	return c.JSON(errcode.ErrCreateOrderSucceed, general.NewMessage(errcode.ErrCreateOrderSucceed))
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.ErrCreateOrderSucceed, map[string]interface{}{"orderid": created.ID, "orderno": created.OrderNo}))
 }
 
 func GetOrders(c echo.Context) error {
 	var (
 		err       error
 		getOrders models.GetOrders
-		orders    *[]models.OrdersGet
+		orders    []models.OrderDetail
+		page      *general.PageInfo
 	)
 
 	if err = c.Bind(&getOrders); err != nil {
@@ -117,7 +114,7 @@ func GetOrders(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrGetOrdersInvalidParams, err.Error())
 	}
 
-	if getOrders.Status != general.OrderUnfinished && getOrders.Status != general.OrderFinished && getOrders.Status != general.OrderGetAll && getOrders.Status != general.OrderCanceled {
+	if getOrders.Status != general.OrderGetAll && !models.ValidOrderStatus(getOrders.Status) {
 		err = errors.New("[ERROR] Invalid Orders Status")
 
 		log.Logger.Error("[ERROR] Error:", err)
@@ -125,19 +122,23 @@ func GetOrders(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrInvalidOrdersStatus, err.Error())
 	}
 
+	if err = getOrders.Normalize(); err != nil {
+		log.Logger.Error("[ERROR] GetOrders Normalize:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetOrdersInvalidParams, err.Error())
+	}
+
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	getOrders.UserID = session.Get(general.SessionUserID).(uint64)
 
-	pageStart := utility.Paging(getOrders.Page, getOrders.PageSize)
-
-	orders, err = models.OrderService.GetOrders(&getOrders, pageStart)
+	orders, page, err = models.OrderService.GetOrders(&getOrders)
 	if err != nil {
 		log.Logger.Error("[ERROR] Mysql error in GetOrders Function:", err)
 
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	if len(*orders) == 0 {
+	if len(orders) == 0 {
 		err = errors.New("[ERROR] Orders Not Found")
 
 		log.Logger.Error("[ERROR] Error:", err)
@@ -145,14 +146,14 @@ func GetOrders(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrNotFound, err.Error())
 	}
 
-	return c.JSON(errcode.ErrGetOrdersSucceed, orders)
+	return c.JSON(http.StatusOK, general.NewMessageWithPage(errcode.ErrGetOrdersSucceed, orders, page))
 }
 
 func GetOneOrder(c echo.Context) error {
 	var (
 		err    error
 		order  models.GetOne
-		OutPut []models.OrmOrders
+		OutPut *models.OrderDetail
 	)
 
 	if err = c.Bind(&order); err != nil {
@@ -161,17 +162,27 @@ func GetOneOrder(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrGetOrderInvalidParams, err.Error())
 	}
 
+	if err = order.Validate(); err != nil {
+		log.Logger.Error("[ERROR] GetOneOrder Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetOrderInvalidParams, err.Error())
+	}
+
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	UserID := session.Get(general.SessionUserID).(uint64)
 
-	OutPut, err = models.OrderService.GetOneOrder(UserID, order.ID)
+	OutPut, err = models.OrderService.GetOneOrder(UserID, &order.OrderRef)
 	if err != nil {
 		log.Logger.Error("[ERROR] GetOneOrder with error:", err)
 
-		return general.NewErrorWithMessage(errcode.ErrNotFound, err.Error())
+		if err == gorm.ErrRecordNotFound {
+			return general.NewErrorWithMessage(errcode.ErrNotFound, err.Error())
+		}
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	return c.JSON(errcode.ErrGetOrderSucceed, general.NewMessageWithData(errcode.GetProInfoSucceed, OutPut))
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.ErrGetOrderSucceed, OutPut))
 }
 
 func ChangeStatus(c echo.Context) error {
@@ -186,18 +197,92 @@ func ChangeStatus(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrChangeOrderInvalidParams, err.Error())
 	}
 
-	if st.Status != general.OrderFinished && st.Status != general.OrderUnfinished && st.Status != general.OrderCanceled && st.Status != general.OrderPaid && st.Status != general.OrderUnpaid {
+	if !models.ValidOrderStatus(st.Status) {
 		err = errors.New("[ERROR] Status InExistent")
 		log.Logger.Error("", err)
 
 		return general.NewErrorWithMessage(errcode.ErrChangeOrderInvalidParams, err.Error())
 	}
-	err = models.OrderService.ChangeStatus(st.OrderID, st.Status)
+
+	if err = st.Validate(); err != nil {
+		log.Logger.Error("[ERROR] ChangeStatus Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeOrderInvalidParams, err.Error())
+	}
+
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	err = models.OrderService.ChangeStatus(userID, &st.OrderRef, st.Status)
 	if err != nil {
 		log.Logger.Error("[ERROR] Change status with error:", err)
 
+		switch err {
+		case gorm.ErrRecordNotFound:
+			return general.NewErrorWithMessage(errcode.ErrNotFound, err.Error())
+		case models.ErrOrderTransition:
+			return general.NewErrorWithMessage(errcode.ErrChangeOrderNotAllowed, err.Error())
+		}
+
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
-	return c.JSON(errcode.ErrChangeOrderSucceed, general.NewMessage(errcode.ErrChangeOrderSucceed))
+	if st.Status == general.OrderSettled {
+		metrics.Payments.Inc()
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ErrChangeOrderSucceed))
+}
+
+// ChangeOrderAddress ships an unpaid order to another address of the user.
+func ChangeOrderAddress(c echo.Context) error {
+	var (
+		err    error
+		change models.ChangeOrderAddress
+	)
+
+	if err = c.Bind(&change); err != nil {
+		log.Logger.Error("[ERROR] ChangeOrderAddress Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeOrderAddressInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(change); err != nil {
+		log.Logger.Error("[ERROR] ChangeOrderAddress Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeOrderAddressInvalidParams, err.Error())
+	}
+
+	if err = change.OrderRef.Validate(); err != nil {
+		log.Logger.Error("[ERROR] ChangeOrderAddress Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeOrderAddressInvalidParams, err.Error())
+	}
+
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	err = models.OrderService.ChangeAddress(userID, &change)
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			log.Logger.Error("[ERROR] ChangeOrderAddress: Order doesn't exist", err)
+
+			return general.NewErrorWithMessage(errcode.ErrChangeOrderAddressNotFound, err.Error())
+		case models.ErrOrderAddressNotFound:
+			log.Logger.Error("[ERROR] ChangeOrderAddress: Address doesn't exist", err)
+
+			return general.NewErrorWithMessage(errcode.ErrChangeOrderAddressAddressNotFound, err.Error())
+		case models.ErrOrderNotEditable:
+			log.Logger.Error("[ERROR] ChangeOrderAddress: Not Editable", err)
+
+			return general.NewErrorWithMessage(errcode.ErrChangeOrderAddressNotEditable, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] ChangeOrderAddress: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] ChangeOrderAddress: User ID %d Order %d %s", userID, change.OrderID, change.OrderNo)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeOrderAddressSucceed))
 }
@@ -35,6 +35,7 @@ package handler
 
 import (
 	"errors"
+	"net/http"
 	"strings"
 
 	"github.com/jinzhu/gorm"
@@ -44,7 +45,6 @@ import (
 	"ShopApi/general/errcode"
 	"ShopApi/log"
 	"ShopApi/models"
-	"ShopApi/utility"
 )
 
 func CreateProduct(c echo.Context) error {
@@ -74,7 +74,7 @@ func CreateProduct(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] CreateProduct: Name %s", product.Name)
 
-	return c.JSON(errcode.CreateProductSucceed, general.NewMessage(errcode.CreateProductSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.CreateProductSucceed))
 }
 
 func GetProductList(c echo.Context) error {
@@ -82,6 +82,7 @@ func GetProductList(c echo.Context) error {
 		err    error
 		header *[]models.ProductList
 		list   *[]models.ProductList
+		tiles  *[]models.CategoryTile
 	)
 
 	header, err = models.ProductService.GetProductHeader()
@@ -98,16 +99,23 @@ func GetProductList(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrGetListDatabase, err.Error())
 	}
 
+	tiles, err = models.ProductService.GetCategoryTiles()
+	if err != nil {
+		log.Logger.Error("[ERROR] GetProductList GetCategoryTiles", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetListDatabase, err.Error())
+	}
+
 	log.Logger.Info("[SUCCEED] GetProductList %v")
 
-	return c.JSON(errcode.GetListSucceed, general.NewMessageForProductList(errcode.GetListSucceed, header, list))
+	return c.JSON(http.StatusOK, general.NewMessageForProductList(errcode.GetListSucceed, header, list, tiles))
 }
 
 func GetProductListByCategory(c echo.Context) error {
 	var (
 		err      error
 		category models.ProductCategory
-		list     *[]models.ProductList
+		page     *models.ProductPage
 	)
 
 	if err = c.Bind(&category); err != nil {
@@ -122,8 +130,13 @@ func GetProductListByCategory(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrGetProductListByCategoryInvalidParams, err.Error())
 	}
 
-	pageStart := utility.Paging(category.Page, category.PageSize)
-	list, err = models.ProductService.GetProductByCategory(category.Category, pageStart, category.PageSize)
+	if err = category.Normalize(); err != nil {
+		log.Logger.Error("[ERROR] GetProductList Normalize", err)
+
+		return general.NewErrorWithMessage(errcode.ErrGetProductListByCategoryInvalidParams, err.Error())
+	}
+
+	page, err = models.ProductService.GetProductByCategory(category.Category, &category.Pagination)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.Logger.Error("[ERROR] GetProductByCategory GetProductByCategory:", err)
@@ -138,7 +151,7 @@ func GetProductListByCategory(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] GetProductByCategory %v")
 
-	return c.JSON(errcode.GetProductListByCategorySucceed, general.NewMessageWithData(errcode.GetProductListByCategorySucceed, list))
+	return c.JSON(http.StatusOK, general.NewMessageWithPage(errcode.GetProductListByCategorySucceed, page.List, page.Page))
 }
 
 func GetProInfo(c echo.Context) error {
@@ -163,8 +176,8 @@ func GetProInfo(c echo.Context) error {
 	productInfo, err = models.ProductService.GetProInfo(productID.ID)
 
 	if err != nil {
-		if err != nil && !strings.Contains(err.Error(), "not found") {
-			log.Logger.Error("[ERROR] GetUserInfo GetUserAvatar:", err)
+		if strings.Contains(err.Error(), "not found") {
+			log.Logger.Error("[ERROR] GetProInfo GetProInfo:", err)
 
 			return general.NewErrorWithMessage(errcode.ErrGetProInfoNotFound, err.Error())
 		}
@@ -176,7 +189,7 @@ func GetProInfo(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] GetProInfo %v")
 
-	return c.JSON(errcode.GetProInfoSucceed, general.NewMessageWithData(errcode.GetProInfoSucceed, productInfo))
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.GetProInfoSucceed, productInfo))
 }
 
 func ChangeProStatus(c echo.Context) error {
@@ -214,7 +227,7 @@ func ChangeProStatus(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] ChangeProStatus")
 
-	return c.JSON(errcode.ChangeStatusSucceed, general.NewMessage(errcode.ChangeStatusSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeStatusSucceed))
 }
 
 func ChangeCategory(c echo.Context) error {
@@ -245,7 +258,7 @@ func ChangeCategory(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] Change Categories: Category %s", cc.Category)
 
-	return c.JSON(errcode.ChangeCategorySucceed, general.NewMessage(errcode.ChangeCategorySucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeCategorySucceed))
 }
 
 func GetMyPage(c echo.Context) error {
@@ -263,5 +276,5 @@ func GetMyPage(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] GetMyPage %v")
 
-	return c.JSON(errcode.GetListSucceed, general.NewMessageWithData(errcode.GetListSucceed, list))
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.GetListSucceed, list))
 }
@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/models"
+)
+
+// SalesReport returns GMV, orders, refunds and cart conversion per day,
+// week or month.
+func SalesReport(c echo.Context) error {
+	var (
+		err   error
+		query models.ReportQuery
+		rows  []models.SalesRow
+	)
+
+	if err = bindReportQuery(c, &query); err != nil {
+		log.Logger.Error("[ERROR] SalesReport Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSalesReportInvalidParams, err.Error())
+	}
+
+	rows, err = models.ReportService.Sales(&query)
+	if err != nil {
+		log.Logger.Error("[ERROR] SalesReport: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.SalesReportSucceed, rows))
+}
+
+// TopProductsReport returns the best selling products of a range.
+func TopProductsReport(c echo.Context) error {
+	var (
+		err   error
+		query models.ReportQuery
+		top   []models.TopProduct
+	)
+
+	if err = bindReportQuery(c, &query); err != nil {
+		log.Logger.Error("[ERROR] TopProductsReport Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrTopProductsReportInvalidParams, err.Error())
+	}
+
+	top, err = models.ReportService.TopProducts(&query)
+	if err != nil {
+		log.Logger.Error("[ERROR] TopProductsReport: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.TopProductsReportSucceed, top))
+}
+
+// TopCategoriesReport returns the best selling categories of a range.
+func TopCategoriesReport(c echo.Context) error {
+	var (
+		err   error
+		query models.ReportQuery
+		top   []models.TopCategory
+	)
+
+	if err = bindReportQuery(c, &query); err != nil {
+		log.Logger.Error("[ERROR] TopCategoriesReport Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrTopCategoriesReportInvalidParams, err.Error())
+	}
+
+	top, err = models.ReportService.TopCategories(&query)
+	if err != nil {
+		log.Logger.Error("[ERROR] TopCategoriesReport: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.TopCategoriesReportSucceed, top))
+}
+
+func bindReportQuery(c echo.Context, query *models.ReportQuery) error {
+	if err := c.Bind(query); err != nil {
+		return err
+	}
+
+	if err := c.Validate(query); err != nil {
+		return err
+	}
+
+	return query.Normalize()
+}
@@ -0,0 +1,175 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/astaxie/session"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/models"
+	"ShopApi/utility"
+)
+
+const (
+	// HeaderDeviceName lets apps name the device a login happens on.
+	HeaderDeviceName = "X-Device-Name"
+
+	// touchInterval is how often the last seen time of a device is written.
+	touchInterval = 5 * time.Minute
+)
+
+// startSession logs the user in on this session and records the device.
+func startSession(c echo.Context, userID uint64) error {
+	req := c.Request()
+
+	device, err := models.SessionService.Start(userID, req.Header.Get(HeaderDeviceName), c.RealIP(), req.UserAgent())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	sess := utility.GlobalSessions.SessionStart(c.Response().Writer, req)
+	sess.Set(general.SessionUserID, userID)
+	sess.Set(general.SessionLoginAt, now.UnixNano())
+	sess.Set(general.SessionDeviceID, device.ID)
+	sess.Set(general.SessionSeenAt, now.Unix())
+	sess.Delete(general.SessionVerifiedAt)
+
+	return nil
+}
+
+// touchSession refreshes the last seen time of the device, at most once per
+// touchInterval.
+func touchSession(sess session.Session, deviceID uint64) {
+	seenAt, _ := sess.Get(general.SessionSeenAt).(int64)
+
+	now := time.Now()
+	if now.Sub(time.Unix(seenAt, 0)) < touchInterval {
+		return
+	}
+
+	sess.Set(general.SessionSeenAt, now.Unix())
+
+	if err := models.SessionService.Touch(deviceID); err != nil {
+		log.Logger.Error("[ERROR] MustLogin Touch:", err)
+	}
+}
+
+// ListSessions shows the devices the user is logged in on.
+func ListSessions(c echo.Context) error {
+	sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := sess.Get(general.SessionUserID).(uint64)
+	current, _ := sess.Get(general.SessionDeviceID).(uint64)
+
+	sessions, err := models.SessionService.List(userID)
+	if err != nil {
+		log.Logger.Error("[ERROR] ListSessions List: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.ListSessionsSucceed, sessions))
+}
+
+// LogoutSession logs one device out, which may be this one.
+func LogoutSession(c echo.Context) error {
+	var (
+		err error
+		id  models.SessionID
+	)
+
+	if err = c.Bind(&id); err != nil {
+		log.Logger.Error("[ERROR] LogoutSession Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrLogoutSessionInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(id); err != nil {
+		log.Logger.Error("[ERROR] LogoutSession Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrLogoutSessionInvalidParams, err.Error())
+	}
+
+	sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := sess.Get(general.SessionUserID).(uint64)
+
+	found, err := models.SessionService.Revoke(userID, id.ID)
+	if err != nil {
+		log.Logger.Error("[ERROR] LogoutSession Revoke:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if !found {
+		err = errors.New("Session doesn't exist.")
+
+		log.Logger.Error("[ERROR] LogoutSession Revoke:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrLogoutSessionNotFound, err.Error())
+	}
+
+	if current, _ := sess.Get(general.SessionDeviceID).(uint64); current == id.ID {
+		sess.Delete(general.SessionUserID)
+	}
+
+	log.Logger.Info("[SUCCEED] LogoutSession: User ID %d Session %d", userID, id.ID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.LogoutSessionSucceed))
+}
+
+// LogoutOtherSessions logs out every device but this one.
+func LogoutOtherSessions(c echo.Context) error {
+	sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := sess.Get(general.SessionUserID).(uint64)
+	current, _ := sess.Get(general.SessionDeviceID).(uint64)
+
+	err := models.SessionService.RevokeOthers(userID, current)
+	if err != nil {
+		log.Logger.Error("[ERROR] LogoutOtherSessions RevokeOthers:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] LogoutOtherSessions: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.LogoutOtherSessionsSucceed))
+}
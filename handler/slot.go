@@ -0,0 +1,264 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/models"
+)
+
+func CreateSlot(c echo.Context) error {
+	var (
+		err    error
+		create models.CreateSlot
+		slot   *models.Slot
+	)
+
+	if err = c.Bind(&create); err != nil {
+		log.Logger.Error("[ERROR] CreateSlot Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrCreateSlotInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(create); err != nil {
+		log.Logger.Error("[ERROR] CreateSlot Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrCreateSlotInvalidParams, err.Error())
+	}
+
+	if !models.ValidSlotPlacement(create.Page, create.Kind) {
+		err = errors.New("Invalid Slot Page Or Kind")
+
+		log.Logger.Error("[ERROR] CreateSlot ValidSlotPlacement:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrCreateSlotInvalidParams, err.Error())
+	}
+
+	if !models.ValidSlotWindow(create.StartAt, create.EndAt) {
+		err = errors.New("Slot Ends Before It Starts")
+
+		log.Logger.Error("[ERROR] CreateSlot ValidSlotWindow:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrCreateSlotInvalidParams, err.Error())
+	}
+
+	slot, err = models.SlotService.CreateSlot(&create)
+	if err != nil {
+		log.Logger.Error("[ERROR] CreateSlot CreateSlot:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] CreateSlot: ID %d", slot.ID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.CreateSlotSucceed, slot))
+}
+
+func ChangeSlot(c echo.Context) error {
+	var (
+		err    error
+		change models.ChangeSlot
+	)
+
+	if err = c.Bind(&change); err != nil {
+		log.Logger.Error("[ERROR] ChangeSlot Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeSlotInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(change); err != nil {
+		log.Logger.Error("[ERROR] ChangeSlot Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeSlotInvalidParams, err.Error())
+	}
+
+	if change.Status != general.SlotOnUse && change.Status != general.SlotNotUse {
+		err = errors.New("Invalid Slot Status")
+
+		log.Logger.Error("[ERROR] ChangeSlot:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeSlotInvalidParams, err.Error())
+	}
+
+	if !models.ValidSlotWindow(change.StartAt, change.EndAt) {
+		err = errors.New("Slot Ends Before It Starts")
+
+		log.Logger.Error("[ERROR] ChangeSlot ValidSlotWindow:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrChangeSlotInvalidParams, err.Error())
+	}
+
+	_, err = models.SlotService.FindSlot(change.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] ChangeSlot FindSlot: Not Found", err)
+
+			return general.NewErrorWithMessage(errcode.ErrChangeSlotNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] ChangeSlot FindSlot: MySQL ERROR", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	err = models.SlotService.ChangeSlot(&change)
+	if err != nil {
+		log.Logger.Error("[ERROR] ChangeSlot ChangeSlot:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] ChangeSlot: ID %d", change.ID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeSlotSucceed))
+}
+
+func DeleteSlot(c echo.Context) error {
+	var (
+		err error
+		id  models.SlotID
+	)
+
+	if err = c.Bind(&id); err != nil {
+		log.Logger.Error("[ERROR] DeleteSlot Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrDeleteSlotInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(id); err != nil {
+		log.Logger.Error("[ERROR] DeleteSlot Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrDeleteSlotInvalidParams, err.Error())
+	}
+
+	_, err = models.SlotService.FindSlot(id.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] DeleteSlot FindSlot: Not Found", err)
+
+			return general.NewErrorWithMessage(errcode.ErrDeleteSlotNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] DeleteSlot FindSlot: MySQL ERROR", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	err = models.SlotService.DeleteSlot(id.ID)
+	if err != nil {
+		log.Logger.Error("[ERROR] DeleteSlot DeleteSlot:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] DeleteSlot: ID %d", id.ID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.DeleteSlotSucceed))
+}
+
+func GetSlots(c echo.Context) error {
+	var (
+		err   error
+		slots *[]models.SlotGet
+	)
+
+	slots, err = models.SlotService.GetSlots(c.QueryParam("page"))
+	if err != nil {
+		log.Logger.Error("[ERROR] GetSlots GetSlots:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] GetSlots %v")
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.GetSlotsSucceed, slots))
+}
+
+func SetSlotItems(c echo.Context) error {
+	var (
+		err  error
+		set  models.SetSlotItems
+		slot *models.Slot
+	)
+
+	if err = c.Bind(&set); err != nil {
+		log.Logger.Error("[ERROR] SetSlotItems Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSetSlotItemsInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(set); err != nil {
+		log.Logger.Error("[ERROR] SetSlotItems Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrSetSlotItemsInvalidParams, err.Error())
+	}
+
+	slot, err = models.SlotService.FindSlot(set.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			log.Logger.Error("[ERROR] SetSlotItems FindSlot: Not Found", err)
+
+			return general.NewErrorWithMessage(errcode.ErrSetSlotItemsNotFound, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] SetSlotItems FindSlot: MySQL ERROR", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	for _, item := range set.Items {
+		if (slot.Kind == general.SlotCategoryTile) != (item.CategoryID != 0 && item.ProductID == 0) {
+			err = errors.New("Category Tiles Take Categories, Other Slots Take Products")
+
+			log.Logger.Error("[ERROR] SetSlotItems:", err)
+
+			return general.NewErrorWithMessage(errcode.ErrSetSlotItemsInvalidParams, err.Error())
+		}
+	}
+
+	err = models.SlotService.SetSlotItems(&set)
+	if err != nil {
+		log.Logger.Error("[ERROR] SetSlotItems SetSlotItems:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] SetSlotItems: ID %d", set.ID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.SetSlotItemsSucceed))
+}
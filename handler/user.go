@@ -36,7 +36,10 @@ package handler
 
 import (
 	"errors"
+	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/labstack/echo"
@@ -44,6 +47,7 @@ import (
 	"ShopApi/general"
 	"ShopApi/general/errcode"
 	"ShopApi/log"
+	"ShopApi/metrics"
 	"ShopApi/models"
 	"ShopApi/utility"
 )
@@ -88,7 +92,9 @@ func Register(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] Register: Mobile %s", *register.Mobile)
 
-	return c.JSON(errcode.RegisterSucceed, general.NewMessage(errcode.RegisterSucceed))
+	metrics.Registrations.Inc()
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.RegisterSucceed))
 }
 
 func Login(c echo.Context) error {
@@ -111,6 +117,20 @@ func Login(c echo.Context) error {
 
 	flag, userID, err := models.UserService.Login(login.Mobile, login.Pass)
 	if err != nil {
+		if locked, ok := err.(*models.ErrUserLocked); ok {
+			log.Logger.Error("[ERROR] Login Login: User locked", err)
+
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(time.Until(locked.Until).Seconds())+1))
+
+			return general.NewErrorWithMessage(errcode.ErrLoginLocked, err.Error())
+		}
+
+		if err == models.ErrUserSuspended {
+			log.Logger.Error("[ERROR] Login Login: User suspended", err)
+
+			return general.NewErrorWithMessage(errcode.ErrLoginSuspended, err.Error())
+		}
+
 		if err == gorm.ErrRecordNotFound {
 			log.Logger.Error("[ERROR] Login Login: User doesn't exist", err)
 
@@ -130,12 +150,15 @@ func Login(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrLoginInvalidPassword, err.Error())
 	}
 
-	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
-	session.Set(general.SessionUserID, userID)
+	if err = startSession(c, userID); err != nil {
+		log.Logger.Error("[ERROR] Login startSession: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
 
 	log.Logger.Info("[SUCCEED] Login: User ID %d", userID)
 
-	return c.JSON(errcode.LoginSucceed, general.NewMessage(errcode.LoginSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.LoginSucceed))
 }
 
 func Logout(c echo.Context) error {
@@ -146,6 +169,12 @@ func Logout(c echo.Context) error {
 	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
 	userID := session.Get(general.SessionUserID)
 
+	if deviceID, ok := session.Get(general.SessionDeviceID).(uint64); ok {
+		if _, err = models.SessionService.Revoke(userID.(uint64), deviceID); err != nil {
+			log.Logger.Error("[ERROR] Logout Revoke:", err)
+		}
+	}
+
 	err = session.Delete(general.SessionUserID)
 	if err != nil {
 		log.Logger.Error("[ERROR] Logout:", err)
@@ -155,7 +184,7 @@ func Logout(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] Logout: User ID %d", userID)
 
-	return c.JSON(errcode.LogoutSucceed, general.NewMessage(errcode.LogoutSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.LogoutSucceed))
 }
 
 func GetUserInfo(c echo.Context) error {
@@ -192,7 +221,7 @@ func GetUserInfo(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] GetUserInfo: User ID %d", userID)
 
-	return c.JSON(errcode.GetUserInfoSucceed, general.NewMessageWithData(errcode.GetUserInfoSucceed, *output))
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.GetUserInfoSucceed, *output))
 }
 
 func ChangeUserInfo(c echo.Context) error {
@@ -219,7 +248,7 @@ func ChangeUserInfo(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] ChangeUserInfo: User ID %d", userID)
 
-	return c.JSON(errcode.ChangeUserInfoSucceed, general.NewMessage(errcode.ChangeUserInfoSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeUserInfoSucceed))
 }
 
 func ChangeUserAvatar(c echo.Context) error {
@@ -252,7 +281,7 @@ func ChangeUserAvatar(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] ChangeUserAvatar: User ID %d", avatar.UserID)
 
-	return c.JSON(errcode.ChangeUserAvatarSucceed, general.NewMessage(errcode.ChangeUserAvatarSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangeUserAvatarSucceed))
 }
 
 func ChangePhone(c echo.Context) error {
@@ -295,6 +324,13 @@ func ChangePhone(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
 	}
 
+	err = models.SessionService.RevokeAll(userID)
+	if err != nil {
+		log.Logger.Error("[ERROR] ChangePhone RevokeAll:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
 	err = session.Delete(general.SessionUserID)
 	if err != nil {
 		log.Logger.Error("[ERROR] ChangePhone Delete:", err)
@@ -304,7 +340,7 @@ func ChangePhone(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] ChangePhone: User ID %d", userID)
 
-	return c.JSON(errcode.ChangePhoneSucceed, general.NewMessage(errcode.ChangePhoneSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangePhoneSucceed))
 }
 
 func ChangePassword(c echo.Context) error {
@@ -352,6 +388,13 @@ func ChangePassword(c echo.Context) error {
 		return general.NewErrorWithMessage(errcode.ErrChangePasswordInvalidParams, err.Error())
 	}
 
+	err = models.SessionService.RevokeAll(userID)
+	if err != nil {
+		log.Logger.Error("[ERROR] ChangePassword RevokeAll:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
 	err = session.Delete(general.SessionUserID)
 	if err != nil {
 		log.Logger.Error("[ERROR] ChangePhone Delete:", err)
@@ -361,5 +404,55 @@ func ChangePassword(c echo.Context) error {
 
 	log.Logger.Info("[SUCCEED] ChangePassword: User ID %d", userID)
 
-	return c.JSON(errcode.ChangePasswordSucceed, general.NewMessage(errcode.ChangePasswordSucceed))
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.ChangePasswordSucceed))
+}
+
+func DeleteAccount(c echo.Context) error {
+	var (
+		deleteAccount models.DeleteAccount
+		err           error
+	)
+
+	if err = c.Bind(&deleteAccount); err != nil {
+		log.Logger.Error("[ERROR] DeleteAccount Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrDeleteAccountInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(deleteAccount); err != nil {
+		log.Logger.Error("[ERROR] DeleteAccount Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrDeleteAccountInvalidParams, err.Error())
+	}
+
+	session := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := session.Get(general.SessionUserID).(uint64)
+
+	ok, err := models.UserService.DeleteAccount(userID, deleteAccount.Password)
+	if err != nil {
+		log.Logger.Error("[ERROR] DeleteAccount DeleteAccount: Database Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	if !ok {
+		err = errors.New("Password is wrong.")
+
+		log.Logger.Error("[ERROR] DeleteAccount:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrDeleteAccountInvalidParams, err.Error())
+	}
+
+	err = models.SessionService.RevokeAll(userID)
+	if err != nil {
+		log.Logger.Error("[ERROR] DeleteAccount RevokeAll:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	utility.GlobalSessions.SessionDestroy(c.Response().Writer, c.Request())
+
+	log.Logger.Info("[SUCCEED] DeleteAccount: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.DeleteAccountSucceed))
 }
@@ -0,0 +1,306 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/astaxie/session"
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+	"ShopApi/models"
+	"ShopApi/utility"
+)
+
+// StepUpPolicy configures step-up verification. Window is how long a
+// verification lasts, Issuer names the shop in authenticator apps and
+// DefaultAddress asks for verification before adding a default address.
+type StepUpPolicy struct {
+	Window         time.Duration
+	Issuer         string
+	DefaultAddress bool
+}
+
+var stepUpPolicy atomic.Value
+
+func init() {
+	SetStepUpPolicy(StepUpPolicy{
+		Window: 5 * time.Minute,
+		Issuer: "ShopApi",
+	})
+}
+
+// SetStepUpPolicy changes the step-up verification policy.
+func SetStepUpPolicy(policy StepUpPolicy) {
+	stepUpPolicy.Store(policy)
+}
+
+func currentStepUpPolicy() StepUpPolicy {
+	return stepUpPolicy.Load().(StepUpPolicy)
+}
+
+// verifiedRecently reports whether the session passed step-up verification
+// within the window. Users without any second factor can only prove it is
+// them by logging in again, so a fresh login counts for them.
+func verifiedRecently(sess session.Session, userID uint64) (bool, error) {
+	since := time.Now().Add(-currentStepUpPolicy().Window)
+
+	if at, _ := sess.Get(general.SessionVerifiedAt).(int64); at > 0 && !time.Unix(0, at).Before(since) {
+		return true, nil
+	}
+
+	factors, err := models.TwoFactorService.Factors(userID)
+	if err != nil {
+		return false, err
+	}
+
+	if len(factors.Methods) > 0 {
+		return false, nil
+	}
+
+	loginAt, _ := sess.Get(general.SessionLoginAt).(int64)
+
+	return loginAt > 0 && !time.Unix(0, loginAt).Before(since), nil
+}
+
+// GetVerifyStatus lists the verification methods of the user and until when
+// the session counts as verified.
+func GetVerifyStatus(c echo.Context) error {
+	sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := sess.Get(general.SessionUserID).(uint64)
+
+	factors, err := models.TwoFactorService.Factors(userID)
+	if err != nil {
+		log.Logger.Error("[ERROR] GetVerifyStatus Factors: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	var until int64
+	if at, ok := sess.Get(general.SessionVerifiedAt).(int64); ok {
+		if expires := time.Unix(0, at).Add(currentStepUpPolicy().Window); expires.After(time.Now()) {
+			until = expires.Unix()
+		}
+	}
+
+	status := map[string]interface{}{
+		"methods":       factors.Methods,
+		"totp":          factors.TOTP,
+		"backupcodes":   factors.BackupCodes,
+		"verifieduntil": until,
+	}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.GetVerifyStatusSucceed, status))
+}
+
+// SendVerifyCode texts a verification code to the phone of the user.
+func SendVerifyCode(c echo.Context) error {
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	err := models.TwoFactorService.SendCode(userID)
+	if err != nil {
+		if err == models.ErrNoSecondFactor {
+			log.Logger.Error("[ERROR] SendVerifyCode SendCode: No Phone", err)
+
+			return general.NewErrorWithMessage(errcode.ErrSendVerifyCodeNoPhone, err.Error())
+		}
+
+		if err == utility.ErrNoSMSSender {
+			log.Logger.Error("[ERROR] SendVerifyCode SendCode: No SMS Sender", err)
+
+			return general.NewErrorWithMessage(errcode.ErrSendVerifyCodeUnavailable, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] SendVerifyCode SendCode:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] SendVerifyCode: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.SendVerifyCodeSucceed))
+}
+
+// Verify checks an SMS, TOTP or backup code and marks the session as
+// verified for the window.
+func Verify(c echo.Context) error {
+	var (
+		err    error
+		stepUp models.StepUp
+	)
+
+	if err = c.Bind(&stepUp); err != nil {
+		log.Logger.Error("[ERROR] Verify Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrVerifyInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(stepUp); err != nil {
+		log.Logger.Error("[ERROR] Verify Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrVerifyInvalidParams, err.Error())
+	}
+
+	sess := utility.GlobalSessions.SessionStart(c.Response().Writer, c.Request())
+	userID := sess.Get(general.SessionUserID).(uint64)
+
+	err = models.TwoFactorService.Verify(userID, stepUp.Method, stepUp.Code)
+	if err != nil {
+		if err == models.ErrWrongCode {
+			log.Logger.Error("[ERROR] Verify Verify: Wrong Code", err)
+
+			return general.NewErrorWithMessage(errcode.ErrVerifyWrongCode, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] Verify Verify:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	now := time.Now()
+	sess.Set(general.SessionVerifiedAt, now.UnixNano())
+
+	log.Logger.Info("[SUCCEED] Verify: User ID %d Method %s", userID, stepUp.Method)
+
+	until := map[string]interface{}{"verifieduntil": now.Add(currentStepUpPolicy().Window).Unix()}
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.VerifySucceed, until))
+}
+
+// EnrollTOTP creates a secret for an authenticator app, it works after
+// ConfirmTOTP.
+func EnrollTOTP(c echo.Context) error {
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	enrolment, err := models.TwoFactorService.Enroll(userID, currentStepUpPolicy().Issuer)
+	if err != nil {
+		if err == models.ErrTOTPEnabled {
+			log.Logger.Error("[ERROR] EnrollTOTP Enroll: Already Enabled", err)
+
+			return general.NewErrorWithMessage(errcode.ErrEnrollTOTPEnabled, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] EnrollTOTP Enroll: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] EnrollTOTP: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.EnrollTOTPSucceed, enrolment))
+}
+
+// ConfirmTOTP turns TOTP on with a code of the enrolled secret and returns
+// the backup codes.
+func ConfirmTOTP(c echo.Context) error {
+	var (
+		err  error
+		code models.TOTPCode
+	)
+
+	if err = c.Bind(&code); err != nil {
+		log.Logger.Error("[ERROR] ConfirmTOTP Bind:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrConfirmTOTPInvalidParams, err.Error())
+	}
+
+	if err = c.Validate(code); err != nil {
+		log.Logger.Error("[ERROR] ConfirmTOTP Validate:", err)
+
+		return general.NewErrorWithMessage(errcode.ErrConfirmTOTPInvalidParams, err.Error())
+	}
+
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	codes, err := models.TwoFactorService.Confirm(userID, code.Code)
+	if err != nil {
+		switch err {
+		case models.ErrTOTPNotEnrolled:
+			log.Logger.Error("[ERROR] ConfirmTOTP Confirm: Not Enrolled", err)
+
+			return general.NewErrorWithMessage(errcode.ErrConfirmTOTPNotEnrolled, err.Error())
+		case models.ErrWrongCode:
+			log.Logger.Error("[ERROR] ConfirmTOTP Confirm: Wrong Code", err)
+
+			return general.NewErrorWithMessage(errcode.ErrConfirmTOTPWrongCode, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] ConfirmTOTP Confirm: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] ConfirmTOTP: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.ConfirmTOTPSucceed, map[string]interface{}{"backupcodes": codes}))
+}
+
+// DisableTOTP removes the authenticator secret and the backup codes.
+func DisableTOTP(c echo.Context) error {
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	err := models.TwoFactorService.Disable(userID)
+	if err != nil {
+		log.Logger.Error("[ERROR] DisableTOTP Disable: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] DisableTOTP: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessage(errcode.DisableTOTPSucceed))
+}
+
+// RegenerateBackupCodes replaces the remaining backup codes with new ones.
+func RegenerateBackupCodes(c echo.Context) error {
+	userID := c.Get(general.ContextUserID).(uint64)
+
+	codes, err := models.TwoFactorService.RegenerateBackupCodes(userID)
+	if err != nil {
+		if err == models.ErrTOTPNotEnrolled {
+			log.Logger.Error("[ERROR] RegenerateBackupCodes: Not Enrolled", err)
+
+			return general.NewErrorWithMessage(errcode.ErrRegenerateBackupCodesNoTOTP, err.Error())
+		}
+
+		log.Logger.Error("[ERROR] RegenerateBackupCodes: Mysql Error", err)
+
+		return general.NewErrorWithMessage(errcode.ErrMysql, err.Error())
+	}
+
+	log.Logger.Info("[SUCCEED] RegenerateBackupCodes: User ID %d", userID)
+
+	return c.JSON(http.StatusOK, general.NewMessageWithData(errcode.RegenerateBackupCodesSucceed, map[string]interface{}{"backupcodes": codes}))
+}
@@ -25,48 +25,168 @@
 /*
  * Revision History:
  *     Initial: 2017/07/18        Yusan Kurban
+ *     Modify : 2026/10/19
  */
+
 package log
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"os"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
 )
 
+var ErrUnknownFormat = errors.New("unknown log format")
+
 // RecordLog is recording log
-type RecordLog struct{}
+type RecordLog struct {
+	zap *zap.Logger
+}
+
+// Config selects the level, format and destination of the logs. An empty
+// File writes to stdout, otherwise the file is rotated once it grows past
+// MaxSize megabytes and MaxBackups old files are kept.
+type Config struct {
+	Level      string
+	Format     string
+	File       string
+	MaxSize    int
+	MaxBackups int
+}
 
 var (
 	Logger *RecordLog
-	zapLog *zap.Logger
+	level  = zap.NewAtomicLevelAt(zapcore.DebugLevel)
 )
 
 func init() {
-	Logger = &RecordLog{}
-	zapLog, _ = zap.NewDevelopment()
+	Logger = newRecordLog(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), zapcore.Lock(os.Stdout))
+}
+
+// Init replaces Logger according to conf.
+func Init(conf Config) error {
+	var (
+		encoder zapcore.Encoder
+		out     zapcore.WriteSyncer = zapcore.Lock(os.Stdout)
+	)
+
+	if conf.Level != "" {
+		if err := SetLevel(conf.Level); err != nil {
+			return err
+		}
+	}
+
+	switch conf.Format {
+	case "", FormatConsole:
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	default:
+		return ErrUnknownFormat
+	}
+
+	if conf.File != "" {
+		w, err := newRotateWriter(conf.File, conf.MaxSize, conf.MaxBackups)
+		if err != nil {
+			return err
+		}
+
+		out = w
+	}
+
+	Logger.Sync()
+	Logger = newRecordLog(encoder, out)
+
+	return nil
+}
+
+func newRecordLog(encoder zapcore.Encoder, out zapcore.WriteSyncer) *RecordLog {
+	core := &redactCore{Core: zapcore.NewCore(encoder, out, level)}
+
+	return &RecordLog{
+		zap: zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zapcore.DPanicLevel)),
+	}
+}
+
+// ValidLevel reports whether name is a level Init and SetLevel accept.
+func ValidLevel(name string) bool {
+	var l zapcore.Level
+
+	return l.UnmarshalText([]byte(name)) == nil
+}
+
+// SetLevel changes the level of every logger, including those made by With.
+func SetLevel(name string) error {
+	var l zapcore.Level
+
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return err
+	}
+
+	level.SetLevel(l)
+
+	return nil
+}
+
+// With returns a logger which adds the key/value pairs to every entry.
+func (l *RecordLog) With(keysAndValues ...interface{}) *RecordLog {
+	return &RecordLog{zap: l.zap.Sugar().With(keysAndValues...).Desugar()}
 }
 
 func (l *RecordLog) Error(desc string, err error) {
-	zapLog.Error(desc, zap.Error(err))
+	l.zap.Error(desc, zap.Error(err))
 }
 
 func (l *RecordLog) Debug(format string, a ...interface{}) {
-	info := fmt.Sprintf(format, a)
-	zapLog.Debug(info, zap.Skip())
+	l.zap.Debug(sprintf(format, a))
 }
 
 func (l *RecordLog) Fatal(v ...interface{}) {
-	log.Fatal(v)
+	l.zap.Fatal(fmt.Sprint(v...))
 }
 
 func (l *RecordLog) Info(format string, a ...interface{}) {
-	info := fmt.Sprintf(format, a)
-	zapLog.Info(info, zap.Skip())
+	l.zap.Info(sprintf(format, a))
 }
 
 func (l *RecordLog) Warn(format string, a ...interface{}) {
-	warn := fmt.Sprintf(format, a)
-	zapLog.Warn(warn, zap.Skip())
+	l.zap.Warn(sprintf(format, a))
+}
+
+// Infow logs msg with key/value pairs, e.g. Infow("login", "userId", id).
+func (l *RecordLog) Infow(msg string, keysAndValues ...interface{}) {
+	l.zap.Sugar().Infow(msg, keysAndValues...)
+}
+
+// Warnw is Infow at warn level.
+func (l *RecordLog) Warnw(msg string, keysAndValues ...interface{}) {
+	l.zap.Sugar().Warnw(msg, keysAndValues...)
+}
+
+// Errorw is Infow at error level.
+func (l *RecordLog) Errorw(msg string, keysAndValues ...interface{}) {
+	l.zap.Sugar().Errorw(msg, keysAndValues...)
+}
+
+// Sync flushes buffered entries.
+func (l *RecordLog) Sync() error {
+	return l.zap.Sync()
+}
+
+// sprintf leaves format alone when there are no arguments, many callers
+// log a fixed message which still contains a verb.
+func sprintf(format string, a []interface{}) string {
+	if len(a) == 0 {
+		return format
+	}
+
+	return fmt.Sprintf(format, a...)
 }
@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package log
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const mask = "******"
+
+var (
+	// secretKeys are field names whose values are never written.
+	secretKeys = []string{"pass", "secret", "token", "otp"}
+
+	// mobile numbers in messages and values keep only the first three and
+	// the last four digits.
+	digits = regexp.MustCompile(`[0-9]+`)
+	mobile = regexp.MustCompile(`^1[3-9][0-9]{9}$`)
+)
+
+// redactCore masks passwords and phone numbers before entries reach the
+// encoder.
+type redactCore struct {
+	zapcore.Core
+}
+
+func (rc *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: rc.Core.With(redactFields(fields))}
+}
+
+func (rc *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if rc.Enabled(ent.Level) {
+		return ce.AddCore(ent, rc)
+	}
+
+	return ce
+}
+
+func (rc *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = maskPhones(ent.Message)
+
+	return rc.Core.Write(ent, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+
+	for i, f := range fields {
+		key := strings.ToLower(f.Key)
+
+		switch {
+		case isSecret(key):
+			out[i] = zap.String(f.Key, mask)
+		case f.Type == zapcore.StringType:
+			out[i] = zap.String(f.Key, maskPhones(f.String))
+		case f.Type == zapcore.ErrorType:
+			out[i] = zap.String(f.Key, maskPhones(f.Interface.(error).Error()))
+		default:
+			out[i] = f
+		}
+	}
+
+	return out
+}
+
+func isSecret(key string) bool {
+	for _, secret := range secretKeys {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func maskPhones(s string) string {
+	return digits.ReplaceAllStringFunc(s, func(run string) string {
+		if !mobile.MatchString(run) {
+			return run
+		}
+
+		return run[:3] + "****" + run[7:]
+	})
+}
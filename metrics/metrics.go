@@ -0,0 +1,345 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// Package metrics keeps counters, histograms and gauges and writes them in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the latency buckets in seconds.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+var (
+	mu         sync.Mutex
+	collectors = map[string]collector{}
+)
+
+func register(c collector) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := collectors[c.name()]; ok {
+		panic("metrics: duplicate metric " + c.name())
+	}
+
+	collectors[c.name()] = c
+}
+
+// WriteTo writes every registered metric ordered by name.
+func WriteTo(out io.Writer) error {
+	mu.Lock()
+	list := make([]collector, 0, len(collectors))
+	for _, c := range collectors {
+		list = append(list, c)
+	}
+	mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name() < list[j].name()
+	})
+
+	w := bufio.NewWriter(out)
+	for _, c := range list {
+		c.write(w)
+	}
+
+	return w.Flush()
+}
+
+type desc struct {
+	metric string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d *desc) name() string {
+	return d.metric
+}
+
+func (d *desc) header(w *bufio.Writer) {
+	w.WriteString("# HELP " + d.metric + " " + escapeHelp(d.help) + "\n")
+	w.WriteString("# TYPE " + d.metric + " " + d.kind + "\n")
+}
+
+func (d *desc) sample(w *bufio.Writer, suffix string, values []string, extra string, v float64) {
+	w.WriteString(d.metric + suffix)
+
+	if len(values) > 0 || extra != "" {
+		pairs := make([]string, 0, len(values)+1)
+		for i, value := range values {
+			pairs = append(pairs, d.labels[i]+"=\""+escapeLabel(value)+"\"")
+		}
+
+		if extra != "" {
+			pairs = append(pairs, extra)
+		}
+
+		w.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	w.WriteString(" " + formatFloat(v) + "\n")
+}
+
+// Counter only goes up.
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		return
+	}
+
+	c.mu.Lock()
+	c.value += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) get() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.value
+}
+
+// CounterVec is a family of counters told apart by label values.
+type CounterVec struct {
+	desc
+	mu       sync.Mutex
+	counters map[string]*Counter
+	values   map[string][]string
+}
+
+// NewCounter registers a counter without labels.
+func NewCounter(name, help string) *Counter {
+	return NewCounterVec(name, help).WithLabelValues()
+}
+
+// NewCounterVec registers a counter family with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	cv := &CounterVec{
+		desc:     desc{metric: name, help: help, kind: "counter", labels: labels},
+		counters: map[string]*Counter{},
+		values:   map[string][]string{},
+	}
+	register(cv)
+
+	return cv
+}
+
+// WithLabelValues returns the counter of the values, given in the order of
+// the label names.
+func (cv *CounterVec) WithLabelValues(values ...string) *Counter {
+	key := strings.Join(values, "\xff")
+
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	c, ok := cv.counters[key]
+	if !ok {
+		c = &Counter{}
+		cv.counters[key] = c
+		cv.values[key] = values
+	}
+
+	return c
+}
+
+func (cv *CounterVec) write(w *bufio.Writer) {
+	cv.header(w)
+
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+
+	for _, key := range sortedKeys(cv.values) {
+		cv.sample(w, "", cv.values[key], "", cv.counters[key].get())
+	}
+}
+
+// Histogram counts observations into buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += v
+	h.count++
+}
+
+// HistogramVec is a family of histograms told apart by label values.
+type HistogramVec struct {
+	desc
+	buckets    []float64
+	mu         sync.Mutex
+	histograms map[string]*Histogram
+	values     map[string][]string
+}
+
+// NewHistogramVec registers a histogram family, buckets are upper bounds in
+// increasing order.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	hv := &HistogramVec{
+		desc:       desc{metric: name, help: help, kind: "histogram", labels: labels},
+		buckets:    buckets,
+		histograms: map[string]*Histogram{},
+		values:     map[string][]string{},
+	}
+	register(hv)
+
+	return hv
+}
+
+func (hv *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	key := strings.Join(values, "\xff")
+
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	h, ok := hv.histograms[key]
+	if !ok {
+		h = &Histogram{buckets: hv.buckets, counts: make([]uint64, len(hv.buckets))}
+		hv.histograms[key] = h
+		hv.values[key] = values
+	}
+
+	return h
+}
+
+func (hv *HistogramVec) write(w *bufio.Writer) {
+	hv.header(w)
+
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+
+	for _, key := range sortedKeys(hv.values) {
+		values := hv.values[key]
+		h := hv.histograms[key]
+
+		h.mu.Lock()
+		for i, upper := range h.buckets {
+			hv.sample(w, "_bucket", values, "le=\""+formatFloat(upper)+"\"", float64(h.counts[i]))
+		}
+		hv.sample(w, "_bucket", values, "le=\"+Inf\"", float64(h.count))
+		hv.sample(w, "_sum", values, "", h.sum)
+		hv.sample(w, "_count", values, "", float64(h.count))
+		h.mu.Unlock()
+	}
+}
+
+// funcMetric reads its value when the metrics are written, for numbers kept
+// elsewhere such as connection pool stats.
+type funcMetric struct {
+	desc
+	fn func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is fn().
+func NewGaugeFunc(name, help string, fn func() float64) {
+	register(&funcMetric{desc: desc{metric: name, help: help, kind: "gauge"}, fn: fn})
+}
+
+// NewCounterFunc registers a counter whose value is fn(), fn must never
+// go down.
+func NewCounterFunc(name, help string, fn func() float64) {
+	register(&funcMetric{desc: desc{metric: name, help: help, kind: "counter"}, fn: fn})
+}
+
+func (fm *funcMetric) write(w *bufio.Writer) {
+	fm.header(w)
+	fm.sample(w, "", nil, "", fm.fn())
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer("\\", "\\\\", "\n", "\\n")
+	labelEscaper = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\"", "\\\"")
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
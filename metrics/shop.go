@@ -0,0 +1,45 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package metrics
+
+var (
+	// HTTP
+	HTTPRequests = NewCounterVec("shop_http_requests_total", "HTTP requests by method, route and status.", "method", "route", "status")
+	HTTPDuration = NewHistogramVec("shop_http_request_duration_seconds", "HTTP request latency by method and route.", DefBuckets, "method", "route")
+	Errors       = NewCounterVec("shop_errors_total", "Failed requests by error key, database errors are under database and mongo.", "error")
+
+	// Cache
+	CacheRequests = NewCounterVec("shop_cache_requests_total", "Cache reads by entity and result, hit or miss.", "entity", "result")
+
+	// Business
+	OrdersCreated = NewCounter("shop_orders_created_total", "Orders created.")
+	Payments      = NewCounter("shop_payments_total", "Orders marked as paid.")
+	Registrations = NewCounter("shop_registrations_total", "Users registered.")
+)
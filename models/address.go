@@ -28,199 +28,360 @@
  *     Modify : 2017/07/20        Yu Yi
  *     Modify : 2017/07/20        Yang Zhengtian
  *     Modify : 2017/07/28        Li Zebang
+ *     Modify : 2026/10/19
  */
 
 package models
 
 import (
+	"errors"
+	"sync/atomic"
 	"time"
 
+	"github.com/jinzhu/gorm"
+
 	"ShopApi/general"
 	"ShopApi/orm"
+	"ShopApi/region"
 	"ShopApi/utility"
 )
 
+var (
+	ErrAreaRequired = errors.New("an area or region codes are required")
+	ErrAddressLimit = errors.New("too many addresses")
+)
+
+// addressLimit is how many addresses a user may keep.
+var addressLimit int64 = 20
+
+// SetAddressLimit changes how many addresses a user may keep.
+func SetAddressLimit(limit int) {
+	atomic.StoreInt64(&addressLimit, int64(limit))
+}
+
 type AddressServiceProvider struct {
 }
 
 var AddressService *AddressServiceProvider = &AddressServiceProvider{}
 
 type Address struct {
-	ID        string    `sql:"primary_key" json:"id"`
+	ID        uint64    `sql:"auto_increment;primary_key" json:"id"`
 	UserID    uint64    `gorm:"column:userid" json:"userid"`
 	Name      string    `json:"name"`
 	Phone     string    `json:"phone"`
 	Area      string    `json:"area"`
+	Province  string    `json:"province"`
+	City      string    `json:"city"`
+	District  string    `json:"district"`
 	Address   string    `json:"address"`
 	Created   time.Time `json:"created"`
 	Updated   time.Time `json:"updated"`
 	IsDefault uint8     `gorm:"column:isdefault" json:"isdefault" `
 }
 
+// AddressJSON.ID is given by the server on AddAddress, it travels as a
+// string like it did when clients made it up.
 type AddressJSON struct {
-	ID        string `json:"id" validate:"required"`
+	ID        uint64 `json:"id,string"`
 	UserID    uint64 `json:"userid"`
 	Name      string `json:"receiver" validate:"required,alphanumunicode"`
 	Phone     string `json:"phone" validate:"required,numeric,len=11"`
-	Area      string `json:"area" validate:"required"`
+	Area      string `json:"area"`
+	Province  string `json:"province" validate:"omitempty,numeric,len=6"`
+	City      string `json:"city" validate:"omitempty,numeric,len=6"`
+	District  string `json:"district" validate:"omitempty,numeric,len=6"`
 	Address   string `json:"detailAdress" validate:"required"`
 	IsDefault bool   `json:"default"`
 }
 
 type AddressID struct {
-	ID string `json:"id" validate:"required"`
+	ID uint64 `json:"id,string" validate:"required"`
 }
 
 func (Address) TableName() string {
 	return "address"
 }
 
-func (asp *AddressServiceProvider) AddAddress(addAddress *AddressJSON) error {
-	var (
-		err     error
-		address Address
-	)
+// ResolveRegion checks the region codes and names the area after them. An
+// address given only as free text keeps it and gets the codes Parse finds.
+func (a *AddressJSON) ResolveRegion() error {
+	if a.Province == "" && a.City == "" && a.District == "" {
+		if a.Area == "" {
+			return ErrAreaRequired
+		}
+
+		m := region.Parse(a.Area)
+		a.Province, a.City, a.District = m.Province, m.City, m.District
+
+		return nil
+	}
+
+	if err := region.Check(a.Province, a.City, a.District); err != nil {
+		return err
+	}
+
+	deepest := region.Match{Province: a.Province, City: a.City, District: a.District}.Deepest()
+	a.Area = region.FullName(deepest)
+
+	return nil
+}
+
+// lockAddresses serialises the address changes of a user for the rest of
+// tx, so the limit and the single default hold under concurrent requests.
+func lockAddresses(tx *gorm.DB, userID uint64) error {
+	var user User
+
+	return tx.Set("gorm:query_option", "FOR UPDATE").Select("id").Where("id = ?", userID).First(&user).Error
+}
+
+// clearDefault leaves the user without a default address, the caller sets
+// a new one in the same transaction.
+func clearDefault(tx *gorm.DB, userID uint64) error {
+	return tx.Model(&Address{}).Where("userid = ? AND isdefault = ?", userID, general.AddressDefault).
+		Update("isdefault", general.AddressNotDefault).Error
+}
+
+// AddAddress saves a new address and returns its ID. The first address of
+// a user is the default whatever the request says.
+func (asp *AddressServiceProvider) AddAddress(addAddress *AddressJSON) (id uint64, err error) {
+	var count int64
 
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
 	}()
 
-	if addAddress.IsDefault {
-		updateToNotDefault := map[string]uint8{"isdefault": general.AddressNotDefault}
+	err = lockAddresses(tx, addAddress.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = tx.Model(&Address{}).Where("userid = ?", addAddress.UserID).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	if count >= atomic.LoadInt64(&addressLimit) {
+		err = ErrAddressLimit
+		return 0, err
+	}
 
-		err = tx.Model(&address).Where("userid = ?", addAddress.UserID).Update(updateToNotDefault).Limit(1).Error
+	isDefault := addAddress.IsDefault || count == 0
+	if isDefault {
+		err = clearDefault(tx, addAddress.UserID)
+		if err != nil {
+			return 0, err
+		}
 	}
 
-	address = Address{
-		ID:        addAddress.ID,
+	address := Address{
 		UserID:    addAddress.UserID,
 		Name:      addAddress.Name,
 		Phone:     addAddress.Phone,
 		Area:      addAddress.Area,
+		Province:  addAddress.Province,
+		City:      addAddress.City,
+		District:  addAddress.District,
 		Address:   addAddress.Address,
 		Created:   time.Now(),
 		Updated:   time.Now(),
-		IsDefault: utility.BoolToUint8(addAddress.IsDefault),
+		IsDefault: utility.BoolToUint8(isDefault),
 	}
 
 	err = tx.Create(&address).Error
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	return address.ID, nil
 }
 
-func (asp *AddressServiceProvider) ChangeAddress(changeAddress *AddressJSON, userID uint64) error {
-	var (
-		err     error
-		address Address
-	)
-
+// ChangeAddress updates an address of the user. It can make the address the
+// default but not take the default away, AlterAddress moves it instead.
+// Making an address the user does not own the default is not found, and
+// the default cleared on the way is rolled back with it.
+func (asp *AddressServiceProvider) ChangeAddress(changeAddress *AddressJSON, userID uint64) (err error) {
 	updater := map[string]interface{}{
-		"name":      changeAddress.Name,
-		"phone":     changeAddress.Phone,
-		"area":      changeAddress.Area,
-		"address":   changeAddress.Address,
-		"updated":   time.Now(),
-		"isdefault": utility.BoolToUint8(changeAddress.IsDefault),
+		"name":     changeAddress.Name,
+		"phone":    changeAddress.Phone,
+		"area":     changeAddress.Area,
+		"province": changeAddress.Province,
+		"city":     changeAddress.City,
+		"district": changeAddress.District,
+		"address":  changeAddress.Address,
+		"updated":  time.Now(),
 	}
 
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
 	}()
 
 	if changeAddress.IsDefault {
-		updateToNotDefault := map[string]uint8{"isdefault": general.AddressNotDefault}
+		err = lockAddresses(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = clearDefault(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		updater["isdefault"] = general.AddressDefault
+	}
 
-		err = tx.Model(&address).Where("userid = ?", userID).Update(updateToNotDefault).Limit(1).Error
+	result := tx.Model(&Address{}).Where("id = ? AND userid = ?", changeAddress.ID, userID).Updates(updater)
+	err = result.Error
+	if err != nil {
+		return err
 	}
 
-	err = tx.Model(&address).Where("id = ?", changeAddress.ID).Update(updater).Limit(1).Error
+	// MySQL does not count a row written with the values it already has,
+	// only a default just cleared above is sure to change.
+	if changeAddress.IsDefault && result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
 
 	return err
 }
 
-func (asp *AddressServiceProvider) GetAddressByUserID(userID uint64) (*[]AddressJSON, error) {
+func (asp *AddressServiceProvider) GetAddressByUserID(userID uint64, p *utility.Pagination) (*[]AddressJSON, *general.PageInfo, error) {
 	var (
 		err         error
+		total       uint64
+		lastKey     string
 		address     []Address
 		addressList []AddressJSON
 	)
 
-	db := orm.Conn
+	query := orm.Conn.Model(&Address{}).Where("userid = ?", userID)
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return &addressList, nil, err
+	}
 
-	err = db.Where("userid = ?", userID).Find(&address).Error
+	err = paginate(query, "id", p).Find(&address).Error
 	if err != nil {
-		return &addressList, err
+		return &addressList, nil, err
 	}
 
-	for _, addr := range address {
+	keep, hasMore := p.Trim(len(address))
+	for _, addr := range address[:keep] {
 		addressGet := AddressJSON{
 			ID:        addr.ID,
 			Name:      addr.Name,
 			Phone:     addr.Phone,
 			Area:      addr.Area,
+			Province:  addr.Province,
+			City:      addr.City,
+			District:  addr.District,
 			Address:   addr.Address,
 			IsDefault: utility.Uint8ToBool(addr.IsDefault),
 		}
 		addressList = append(addressList, addressGet)
+		lastKey = uintKey(addr.ID)
 	}
 
-	return &addressList, nil
+	return &addressList, p.Info(total, hasMore, lastKey), nil
 }
 
+// AlterAddress makes the address the only default of the user. An address
+// the user does not own is not found and the old default stays.
 func (asp *AddressServiceProvider) AlterAddress(alterAddress *AddressID, userID uint64) (err error) {
-	var (
-		address Address
-	)
-
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
 	}()
 
-	updateToNotDefault := map[string]interface{}{"isdefault": general.AddressNotDefault}
-
-	err = tx.Model(&address).Where("userid = ?", userID).Update(updateToNotDefault).Limit(1).Error
+	err = lockAddresses(tx, userID)
 	if err != nil {
 		return err
 	}
 
-	updaterToDefault := map[string]uint8{"isdefault": general.AddressDefault}
+	err = clearDefault(tx, userID)
+	if err != nil {
+		return err
+	}
 
-	err = tx.Model(&address).Where("id = ?", alterAddress.ID).Update(updaterToDefault).Limit(1).Error
+	result := tx.Model(&Address{}).Where("id = ? AND userid = ?", alterAddress.ID, userID).
+		Update("isdefault", general.AddressDefault)
+	err = result.Error
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if result.RowsAffected == 0 {
+		err = gorm.ErrRecordNotFound
+	}
+
+	return err
 }
 
-func (asp *AddressServiceProvider) DeleteAddress(deleteAddress *AddressID) error {
+// DeleteAddress removes an address of the user. When it was the default,
+// the most recently updated remaining address takes over.
+func (asp *AddressServiceProvider) DeleteAddress(deleteAddress *AddressID, userID uint64) (err error) {
 	var (
 		address Address
+		next    Address
 	)
 
-	address.ID = deleteAddress.ID
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = lockAddresses(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Where("id = ? AND userid = ?", deleteAddress.ID, userID).First(&address).Error
+	if err != nil {
+		return err
+	}
 
-	db := orm.Conn
+	err = tx.Delete(&address).Error
+	if err != nil {
+		return err
+	}
+
+	if address.IsDefault != general.AddressDefault {
+		return nil
+	}
+
+	err = tx.Where("userid = ?", userID).Order("updated DESC, id DESC").First(&next).Error
+	if err == gorm.ErrRecordNotFound {
+		err = nil
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&next).Update("isdefault", general.AddressDefault).Error
 
-	return db.Delete(&address).Error
+	return err
 }
 
-func (asp *AddressServiceProvider) FindAddress(ID string, userID uint64) error {
+func (asp *AddressServiceProvider) FindAddress(ID uint64, userID uint64) error {
 	var (
 		address Address
 	)
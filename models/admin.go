@@ -0,0 +1,96 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"time"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+	"ShopApi/utility"
+)
+
+type AdminServiceProvider struct {
+}
+
+var AdminService *AdminServiceProvider = &AdminServiceProvider{}
+
+// Admin accounts are created directly in the database, Password holds a
+// hash made by utility.GenerateHash.
+type Admin struct {
+	ID       uint64     `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	Username string     `json:"username"`
+	Password string     `json:"-"`
+	Email    string     `json:"email"`
+	Name     string     `json:"name"`
+	Status   uint8      `json:"status"`
+	Created  time.Time  `json:"created"`
+	Updated  *time.Time `json:"updated"`
+}
+
+type AdminLogin struct {
+	Username *string `json:"username" validate:"required,min=2,max=64"`
+	Pass     *string `json:"password" validate:"required,min=6,max=64"`
+}
+
+func (Admin) TableName() string {
+	return "admin"
+}
+
+// Login checks the password of an active admin.
+func (as *AdminServiceProvider) Login(username, pass *string) (bool, uint64, error) {
+	var (
+		admin Admin
+		err   error
+	)
+
+	err = orm.Conn.Where("username = ? AND status = ?", *username, general.AdminActive).First(&admin).Error
+	if err != nil {
+		return false, 0, err
+	}
+
+	if !utility.CompareHash([]byte(admin.Password), *pass) {
+		return false, 0, nil
+	}
+
+	return true, admin.ID, nil
+}
+
+// IsActive reports whether the admin may still use the console.
+func (as *AdminServiceProvider) IsActive(adminID uint64) (bool, error) {
+	var admin Admin
+
+	err := orm.Conn.Select("id, status").Where("id = ?", adminID).First(&admin).Error
+	if err != nil {
+		return false, err
+	}
+
+	return admin.Status == general.AdminActive, nil
+}
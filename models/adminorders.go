@@ -0,0 +1,377 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+	"ShopApi/utility"
+)
+
+var ErrOrderTransition = errors.New("order can't move to that status")
+
+// exportBatch is how many orders ExportOrders loads at a time.
+const exportBatch = 500
+
+// orderTransitions are the statuses staff may move an order to from each
+// status. A received order is finished once it can no longer be returned
+// by the customer, a return that has been handled ends as canceled.
+var orderTransitions = map[uint8][]uint8{
+	general.OrderUnfinished: {general.OrderSettled, general.OrderCanceled},
+	general.OrderSettled:    {general.OrderShipped, general.OrderCanceled},
+	general.OrderShipped:    {general.OrderReceived},
+	general.OrderReceived:   {general.OrderFinished, general.OrderReturning},
+	general.OrderFinished:   {general.OrderReturning},
+	general.OrderReturning:  {general.OrderCanceled},
+}
+
+// OrderNote is an internal note staff leave on an order, customers never
+// see them.
+type OrderNote struct {
+	ID      uint64    `sql:"auto_increment;primary_key" json:"id"`
+	OrderID uint64    `gorm:"column:orderid" json:"-"`
+	AdminID uint64    `gorm:"column:adminid" json:"adminid"`
+	Note    string    `json:"note"`
+	Created time.Time `json:"created"`
+}
+
+// AdminOrderQuery filters orders of all users. Phone matches the phone of
+// the account or the receiver, OrderNo may be a prefix.
+type AdminOrderQuery struct {
+	Status    *uint8     `json:"status"`
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+	UserID    uint64     `json:"userid"`
+	Phone     string     `json:"phone" validate:"omitempty,numeric,max=16"`
+	OrderNo   string     `json:"orderno" validate:"omitempty,numeric,max=18"`
+	ProductID uint64     `json:"productid"`
+	MinAmount *float64   `json:"minamount"`
+	MaxAmount *float64   `json:"maxamount"`
+	utility.Pagination
+}
+
+// AdminOrderDetail is an order as staff see it.
+type AdminOrderDetail struct {
+	OrderDetail
+	UserID uint64      `json:"userid"`
+	Notes  []OrderNote `json:"notes,omitempty"`
+}
+
+type AddOrderNote struct {
+	OrderRef
+	Note string `json:"note" validate:"required,max=1000"`
+}
+
+// AdminChangeStatus moves several orders to one status, shipping details
+// are kept when orders are marked shipped.
+type AdminChangeStatus struct {
+	Status uint8              `json:"status"`
+	Orders []AdminStatusOrder `json:"orders" validate:"required,min=1,max=100,dive"`
+}
+
+type AdminStatusOrder struct {
+	OrderRef
+	Carrier    string `json:"carrier" validate:"max=64"`
+	TrackingNo string `json:"trackingno" validate:"max=64"`
+}
+
+// AdminStatusResult tells how one order of an AdminChangeStatus went.
+type AdminStatusResult struct {
+	OrderID uint64 `json:"orderid"`
+	OrderNo string `json:"orderno"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (OrderNote) TableName() string {
+	return "ordernote"
+}
+
+// Validate checks the ranges of the query.
+func (q *AdminOrderQuery) Validate() error {
+	if q.Status != nil && !ValidOrderStatus(*q.Status) {
+		return errors.New("invalid order status")
+	}
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return errors.New("from must be before to")
+	}
+
+	if q.MinAmount != nil && q.MaxAmount != nil && *q.MinAmount > *q.MaxAmount {
+		return errors.New("minamount is above maxamount")
+	}
+
+	return nil
+}
+
+func (q *AdminOrderQuery) where(query *gorm.DB) *gorm.DB {
+	if q.Status != nil {
+		query = query.Where("status = ?", *q.Status)
+	}
+
+	if q.From != nil {
+		query = query.Where("created >= ?", *q.From)
+	}
+
+	if q.To != nil {
+		query = query.Where("created < ?", *q.To)
+	}
+
+	if q.UserID != 0 {
+		query = query.Where("userid = ?", q.UserID)
+	}
+
+	if q.Phone != "" {
+		query = query.Where("phone = ? OR userid IN (SELECT userid FROM userinfo WHERE phone = ?)", q.Phone, q.Phone)
+	}
+
+	if q.OrderNo != "" {
+		query = query.Where("orderno LIKE ?", q.OrderNo+"%")
+	}
+
+	if q.ProductID != 0 {
+		query = query.Where("id IN (SELECT orderid FROM orderproduct WHERE productid = ?)", q.ProductID)
+	}
+
+	if q.MinAmount != nil {
+		query = query.Where("totalprice >= ?", *q.MinAmount)
+	}
+
+	if q.MaxAmount != nil {
+		query = query.Where("totalprice <= ?", *q.MaxAmount)
+	}
+
+	return query
+}
+
+// CanTransition reports whether staff may move an order from one status to
+// another.
+func CanTransition(from, to uint8) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func adminDetails(orders []Orders, history bool) ([]AdminOrderDetail, error) {
+	details, err := orderDetails(orders, history)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := make([]AdminOrderDetail, len(details))
+	for i := range details {
+		admin[i] = AdminOrderDetail{
+			OrderDetail: details[i],
+			UserID:      orders[i].UserID,
+		}
+	}
+
+	return admin, nil
+}
+
+// AdminOrders lists the orders of all users matching q.
+func (osp *OrderServiceProvider) AdminOrders(q *AdminOrderQuery) ([]AdminOrderDetail, *general.PageInfo, error) {
+	var (
+		err     error
+		total   uint64
+		lastKey string
+		orders  []Orders
+	)
+
+	query := q.where(orm.Conn.Model(&Orders{}))
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = paginate(query, "id", &q.Pagination).Find(&orders).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keep, hasMore := q.Trim(len(orders))
+	orders = orders[:keep]
+	if keep > 0 {
+		lastKey = uintKey(orders[keep-1].ID)
+	}
+
+	details, err := adminDetails(orders, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return details, q.Info(total, hasMore, lastKey), nil
+}
+
+// AdminOrder returns any order with its history and internal notes.
+func (osp *OrderServiceProvider) AdminOrder(ref *OrderRef) (*AdminOrderDetail, error) {
+	var order Orders
+
+	err := ref.where(orm.Conn).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := adminDetails([]Orders{order}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	err = orm.Conn.Where("orderid = ?", order.ID).Order("id").Find(&details[0].Notes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &details[0], nil
+}
+
+// AddNote leaves an internal note of an admin on an order.
+func (osp *OrderServiceProvider) AddNote(adminID uint64, add *AddOrderNote) (*OrderNote, error) {
+	var order Orders
+
+	err := add.where(orm.Conn).Select("id").First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+
+	note := OrderNote{
+		OrderID: order.ID,
+		AdminID: adminID,
+		Note:    add.Note,
+		Created: time.Now(),
+	}
+
+	err = orm.Conn.Create(&note).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// AdminChangeStatus moves every order on its own, so one order which can't
+// move doesn't hold back the others. The result of each order is returned
+// in the order they were given.
+func (osp *OrderServiceProvider) AdminChangeStatus(change *AdminChangeStatus) []AdminStatusResult {
+	results := make([]AdminStatusResult, len(change.Orders))
+
+	for i := range change.Orders {
+		o := &change.Orders[i]
+
+		results[i] = AdminStatusResult{OrderID: o.OrderID, OrderNo: o.OrderNo}
+
+		order, err := transition(o, change.Status)
+		if order != nil {
+			results[i].OrderID = order.ID
+			results[i].OrderNo = order.OrderNo
+		}
+
+		if err != nil {
+			results[i].Error = err.Error()
+		}
+	}
+
+	return results
+}
+
+func transition(o *AdminStatusOrder, status uint8) (order *Orders, err error) {
+	order = &Orders{}
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = o.where(tx.Set("gorm:query_option", "FOR UPDATE")).First(order).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if !CanTransition(order.Status, status) {
+		err = ErrOrderTransition
+		return order, err
+	}
+
+	var ship *OrderShipment
+	if status == general.OrderShipped {
+		ship = &OrderShipment{Carrier: o.Carrier, TrackingNo: o.TrackingNo}
+	}
+
+	err = setStatus(tx, order.ID, status, ship)
+
+	return order, err
+}
+
+// ExportOrders hands the orders matching q to write a batch at a time, in
+// the order they were placed. Paging of q is ignored.
+func (osp *OrderServiceProvider) ExportOrders(q *AdminOrderQuery, write func([]AdminOrderDetail) error) error {
+	var lastKey uint64
+
+	for {
+		var orders []Orders
+
+		err := q.where(orm.Conn).Where("id > ?", lastKey).Order("id").Limit(exportBatch).Find(&orders).Error
+		if err != nil {
+			return err
+		}
+
+		if len(orders) == 0 {
+			return nil
+		}
+
+		lastKey = orders[len(orders)-1].ID
+
+		details, err := adminDetails(orders, false)
+		if err != nil {
+			return err
+		}
+
+		if err = write(details); err != nil {
+			return err
+		}
+
+		if len(orders) < exportBatch {
+			return nil
+		}
+	}
+}
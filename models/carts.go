@@ -30,6 +30,7 @@
  *     Modify : 2017/07/24       Ma Chao
  *     Modify : 2017/08/10       Zhang Zizhao
  *     Modify : 2017/08/12       Yu Yi
+ *     Modify : 2026/10/19
  */
 
 package models
@@ -37,10 +38,11 @@ package models
 import (
 	"time"
 
-	"gopkg.in/mgo.v2/bson"
+	"github.com/jinzhu/gorm"
 
 	"ShopApi/general"
 	"ShopApi/orm"
+	"ShopApi/utility"
 )
 
 type CartsServiceProvider struct {
@@ -112,31 +114,13 @@ func (cs *CartsServiceProvider) CreateCarts(carts *CartPutIn, userID uint64, nam
 		Size:      carts.Size,
 		Color:     carts.Color,
 		Status:    general.ProInCart,
+		PayStatus: general.ProNotBought,
 		Created:   time.Now(),
 	}
 
 	err = db.Where("productid = ? AND userid = ? AND size = ? AND color = ? AND status = ?", carts.ProductID, userID, carts.Size, carts.Color, general.ProInCart).First(&cart).Error
 	if err != nil {
-		tx := db.Begin()
-		defer func() {
-			if err != nil {
-				err = tx.Rollback().Error
-			} else {
-				err = tx.Commit().Error
-			}
-		}()
-
-		err = tx.Create(&cartsPutIn).Error
-		if err != nil {
-			return err
-		}
-
-		err = tx.Commit().Error
-		if err != nil {
-			return err
-		}
-
-		return err
+		return db.Create(&cartsPutIn).Error
 	}
 	count := carts.Count + cart.Count
 
@@ -145,16 +129,13 @@ func (cs *CartsServiceProvider) CreateCarts(carts *CartPutIn, userID uint64, nam
 	return err
 }
 
-func (cs *CartsServiceProvider) CartsDelete(data *CartsDelete, userID uint64) error {
-	var (
-		cart Cart
-		err  error
-	)
+func (cs *CartsServiceProvider) CartsDelete(data *CartsDelete, userID uint64) (err error) {
+	var cart Cart
 
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
@@ -170,6 +151,31 @@ func (cs *CartsServiceProvider) CartsDelete(data *CartsDelete, userID uint64) er
 	return err
 }
 
+// cartsOrdered takes the items bought with an order out of the cart inside
+// the transaction creating the order. They are kept marked with the order
+// so reports can tell carts which turned into orders. An item which is not
+// in the cart fails the order with ErrOrderCartNotFound.
+func cartsOrdered(tx *gorm.DB, data *CartsDelete, userID, orderID uint64) error {
+	updater := map[string]interface{}{
+		"status":    general.ProNotInCart,
+		"paystatus": general.ProBought,
+		"orderid":   orderID,
+	}
+
+	for _, item := range data.Data {
+		result := tx.Model(&Cart{}).Where("userid = ? AND productid = ? AND size = ? AND color = ? AND status = ?", userID, item.ProductID, item.Size, item.Color, general.ProInCart).Updates(updater)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrOrderCartNotFound
+		}
+	}
+
+	return nil
+}
+
 func (cs *CartsServiceProvider) AlterCartPro(carts *CartPutIn) error {
 	var (
 		cart Cart
@@ -179,34 +185,49 @@ func (cs *CartsServiceProvider) AlterCartPro(carts *CartPutIn) error {
 	db := orm.Conn
 
 	updater := map[string]interface{}{"count": carts.Count}
-	err = db.Model(&cart).Where("productid = ? AND size = ? AND color = ?", carts.ProductID, carts.Size, carts.Color).Update(updater).Limit(1).Error
+	err = db.Model(&cart).Where("productid = ? AND size = ? AND color = ? AND status = ?", carts.ProductID, carts.Size, carts.Color, general.ProInCart).Update(updater).Limit(1).Error
 
 	return err
 }
 
-func (cs *CartsServiceProvider) CartsBrowse(userID uint64) (*[]ConCarts, error) {
+func (cs *CartsServiceProvider) CartsBrowse(userID uint64, p *utility.Pagination) (*[]ConCarts, *general.PageInfo, error) {
 	var (
-		err   error
-		cart  []Cart
-		image ProductImages
-		list  []ConCarts
+		err     error
+		total   uint64
+		lastKey string
+		cart    []Cart
+		list    []ConCarts
 	)
 
-	db := orm.Conn
+	query := orm.Conn.Model(&Cart{}).Where("status = ? AND userid = ?", general.ProInCart, userID)
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return &list, nil, err
+	}
 
-	err = db.Where("status = ? AND userid = ?", general.ProInCart, userID).Find(&cart).Error
+	err = paginate(query, "id", p).Find(&cart).Error
 	if err != nil {
-		return &list, err
+		return &list, nil, err
 	}
 
-	collection := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
+	keep, hasMore := p.Trim(len(cart))
+	cart = cart[:keep]
+	if keep > 0 {
+		lastKey = uintKey(cart[keep-1].ID)
+	}
+
+	ids := make([]uint64, len(cart))
+	for i, value := range cart {
+		ids[i] = value.ProductID
+	}
+
+	avatars, err := LoadAvatars(ids, general.ProductAvatar)
+	if err != nil {
+		return &list, nil, err
+	}
 
 	for _, value := range cart {
-		err = collection.Find(bson.M{"productid": value.ProductID, "class": general.ProductAvatar}).One(&image)
-		if err != nil {
-			return &list, err
-		}
 		lis := ConCarts{
 			ProductID: value.ProductID,
 			Name:      value.Name,
@@ -214,10 +235,10 @@ func (cs *CartsServiceProvider) CartsBrowse(userID uint64) (*[]ConCarts, error)
 			Count:     value.Count,
 			Size:      value.Size,
 			Price:     value.Price,
-			Avatar:    image.Image,
+			Avatar:    avatars[value.ProductID],
 		}
 		list = append(list, lis)
 	}
 
-	return &list, err
+	return &list, p.Info(total, hasMore, lastKey), nil
 }
@@ -0,0 +1,121 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"ShopApi/general"
+	"ShopApi/server/initcache"
+	"ShopApi/utility"
+)
+
+// Catalog reads go through the cache, mutations of products and categories
+// invalidate what they change.
+
+func (ps *ProductServiceProvider) GetProductHeader() (*[]ProductList, error) {
+	var header []ProductList
+
+	err := initcache.ProductListEntity.Fetch(&header, func() (interface{}, error) {
+		return slotProducts(general.SlotPageHome, general.SlotBanner, general.ProductImage, ps.productHeader)
+	}, "header")
+
+	return &header, err
+}
+
+func (ps *ProductServiceProvider) GetProductList() (*[]ProductList, error) {
+	var list []ProductList
+
+	err := initcache.ProductListEntity.Fetch(&list, func() (interface{}, error) {
+		return slotProducts(general.SlotPageHome, general.SlotFeatured, general.ProductAvatar, ps.productList)
+	}, "list")
+
+	return &list, err
+}
+
+func (ps *ProductServiceProvider) GetMyPage() (*[]ProductList, error) {
+	var list []ProductList
+
+	err := initcache.ProductListEntity.Fetch(&list, func() (interface{}, error) {
+		return slotProducts(general.SlotPageMine, general.SlotFeatured, general.ProductAvatar, ps.myPage)
+	}, "mypage")
+
+	return &list, err
+}
+
+func (ps *ProductServiceProvider) GetCategoryTiles() (*[]CategoryTile, error) {
+	var tiles []CategoryTile
+
+	err := initcache.ProductListEntity.Fetch(&tiles, func() (interface{}, error) {
+		return categoryTiles()
+	}, "tiles")
+
+	return &tiles, err
+}
+
+func (ps *ProductServiceProvider) GetProductByCategory(cate uint64, p *utility.Pagination) (*ProductPage, error) {
+	var page ProductPage
+
+	err := initcache.ProductListEntity.Fetch(&page, func() (interface{}, error) {
+		return ps.productByCategory(cate, p)
+	}, "category", cate, p.Page, p.PageSize, p.After())
+
+	return &page, err
+}
+
+func (ps *ProductServiceProvider) GetProInfo(id uint64) (*ProductInfo, error) {
+	var info ProductInfo
+
+	err := initcache.ProductEntity.Fetch(&info, func() (interface{}, error) {
+		return ps.proInfo(id)
+	}, id)
+
+	return &info, err
+}
+
+func (csp *CategoryServiceProvider) GetCategory(p *utility.Pagination) (*CategoryPage, error) {
+	var page CategoryPage
+
+	err := initcache.CategoryEntity.Fetch(&page, func() (interface{}, error) {
+		return csp.categoryList(p)
+	}, "list", p.Page, p.PageSize, p.After())
+
+	return &page, err
+}
+
+func invalidateProduct(id uint64) {
+	initcache.ProductEntity.Invalidate(id)
+	initcache.ProductListEntity.InvalidateAll()
+}
+
+// invalidateCategories drops the product lists with the categories, the
+// category tiles are cached among them.
+func invalidateCategories() {
+	initcache.CategoryEntity.InvalidateAll()
+	initcache.ProductListEntity.InvalidateAll()
+}
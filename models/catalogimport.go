@@ -0,0 +1,633 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+)
+
+const (
+	// MaxImportRows is how many products one file may hold.
+	MaxImportRows = 5000
+
+	// maxImportErrors is how many row errors a job keeps, the counts are
+	// always complete.
+	maxImportErrors = 1000
+
+	// importProgress is how often a running job saves its counts.
+	importProgress = 100
+
+	// catalogListSep separates the values of a list cell.
+	catalogListSep = "|"
+
+	// catalogPathSep separates the levels of a category path.
+	catalogPathSep = "/"
+)
+
+// catalogColumns are the columns of an import or export, in the order an
+// export writes them. Only the required ones have to be in an import.
+var catalogColumns = []struct {
+	name     string
+	required bool
+}{
+	{"id", false},
+	{"name", true},
+	{"category", true},
+	{"price", true},
+	{"stock", false},
+	{"sizes", true},
+	{"colors", true},
+	{"avatar", false},
+	{"images", false},
+	{"detailimages", false},
+	{"detail", true},
+}
+
+var ErrImportHeader = errors.New("import file has no header row")
+
+// ImportJob is a catalog import running in the background.
+type ImportJob struct {
+	ID        uint64     `sql:"auto_increment;primary_key" json:"id"`
+	AdminID   uint64     `gorm:"column:adminid" json:"adminid"`
+	Filename  string     `json:"filename"`
+	DryRun    bool       `gorm:"column:dryrun" json:"dryrun"`
+	Status    uint8      `json:"status"`
+	Message   string     `json:"message"`
+	Total     int        `json:"total"`
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+	Errors    string     `json:"-"`
+	Created   time.Time  `json:"created"`
+	Finished  *time.Time `json:"finished"`
+
+	RowErrors []ImportRowError `gorm:"-" json:"errors"`
+}
+
+// ImportRowError tells why a row was not imported, rows are numbered as a
+// spreadsheet program shows them with the header as row 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type CatalogExport struct {
+	Format string `json:"format"`
+}
+
+type ImportJobID struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+// catalogRow is a row which passed validation.
+type catalogRow struct {
+	ID      uint64
+	Product CreateProduct
+}
+
+func (ImportJob) TableName() string {
+	return "importjob"
+}
+
+// CreateImportJob records a queued import, RunImport does the work.
+func (ps *ProductServiceProvider) CreateImportJob(adminID uint64, filename string, dryRun bool) (*ImportJob, error) {
+	job := ImportJob{
+		AdminID:  adminID,
+		Filename: filename,
+		DryRun:   dryRun,
+		Status:   general.ImportQueued,
+		Created:  time.Now(),
+	}
+
+	err := orm.Conn.Create(&job).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// ImportJob returns a job with its row errors.
+func (ps *ProductServiceProvider) ImportJob(id uint64) (*ImportJob, error) {
+	var job ImportJob
+
+	err := orm.Conn.Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+
+	job.RowErrors = []ImportRowError{}
+	if job.Errors != "" {
+		if err = json.Unmarshal([]byte(job.Errors), &job.RowErrors); err != nil {
+			return nil, err
+		}
+	}
+
+	return &job, nil
+}
+
+// RunImport checks every row of the file and, unless the job is a dry run,
+// creates a product for each valid row without an id and updates the
+// product of each valid row with one. Rows are saved one by one, a bad row
+// doesn't keep the others out.
+func (ps *ProductServiceProvider) RunImport(job *ImportJob, rows [][]string) error {
+	var rowErrors []ImportRowError
+
+	report := func(row int, column, message string) {
+		if len(rowErrors) < maxImportErrors {
+			rowErrors = append(rowErrors, ImportRowError{Row: row, Column: column, Message: message})
+		}
+	}
+
+	err := orm.Conn.Model(job).Update("status", general.ImportRunning).Error
+	if err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		return finishImport(job, nil, ErrImportHeader)
+	}
+
+	columns, err := catalogHeader(rows[0])
+	if err != nil {
+		return finishImport(job, nil, err)
+	}
+
+	paths, err := categoryPaths()
+	if err != nil {
+		return finishImport(job, nil, err)
+	}
+
+	existing, err := existingProducts(rows[1:], columns)
+	if err != nil {
+		return finishImport(job, nil, err)
+	}
+
+	for i, cells := range rows[1:] {
+		n := i + 2
+
+		if emptyRow(cells) {
+			continue
+		}
+
+		job.Total++
+
+		row, column, err := parseCatalogRow(cells, columns, paths, existing)
+		if err == nil && !job.DryRun {
+			err = saveCatalogRow(row)
+		}
+
+		if err != nil {
+			job.Failed++
+			report(n, column, err.Error())
+		} else {
+			job.Succeeded++
+		}
+
+		if job.Total%importProgress == 0 {
+			updater := map[string]interface{}{
+				"total":     job.Total,
+				"succeeded": job.Succeeded,
+				"failed":    job.Failed,
+			}
+
+			if err = orm.Conn.Model(job).Updates(updater).Error; err != nil {
+				return finishImport(job, rowErrors, err)
+			}
+		}
+	}
+
+	return finishImport(job, rowErrors, nil)
+}
+
+// finishImport saves the outcome of a job, a failure is the whole file
+// being unusable rather than a bad row.
+func finishImport(job *ImportJob, rowErrors []ImportRowError, failure error) error {
+	now := time.Now()
+
+	errorsJSON := ""
+	if len(rowErrors) > 0 {
+		b, err := json.Marshal(rowErrors)
+		if err != nil {
+			return err
+		}
+
+		errorsJSON = string(b)
+	}
+
+	updater := map[string]interface{}{
+		"status":    general.ImportDone,
+		"message":   "",
+		"total":     job.Total,
+		"succeeded": job.Succeeded,
+		"failed":    job.Failed,
+		"errors":    errorsJSON,
+		"finished":  now,
+	}
+
+	if failure != nil {
+		updater["status"] = general.ImportFailed
+		updater["message"] = failure.Error()
+	}
+
+	return orm.Conn.Model(job).Updates(updater).Error
+}
+
+// catalogHeader maps column names to their position in the file.
+func catalogHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	var missing []string
+	for _, c := range catalogColumns {
+		if _, ok := columns[c.name]; c.required && !ok {
+			missing = append(missing, c.name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("import file misses the columns %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+// categoryPaths maps the path of every category, like "女装/连衣裙", to its
+// ID.
+func categoryPaths() (map[string]uint64, error) {
+	var categories []Category
+
+	err := orm.Conn.Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint64]*Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	paths := make(map[string]uint64, len(categories))
+	for id := range byID {
+		if path, ok := categoryPath(byID, id); ok {
+			paths[path] = id
+		}
+	}
+
+	return paths, nil
+}
+
+// categoryPath walks up from a category to the top, categories whose
+// parents are missing or loop have no path.
+func categoryPath(byID map[uint64]*Category, id uint64) (string, bool) {
+	var names []string
+
+	for steps := 0; id != 0; steps++ {
+		c, ok := byID[id]
+		if !ok || steps > len(byID) {
+			return "", false
+		}
+
+		names = append([]string{c.Name}, names...)
+		id = c.PID
+	}
+
+	return strings.Join(names, catalogPathSep), true
+}
+
+// existingProducts finds which of the ids in the file are products.
+func existingProducts(rows [][]string, columns map[string]int) (map[uint64]bool, error) {
+	var (
+		ids   []uint64
+		found []uint64
+	)
+
+	col, ok := columns["id"]
+	if !ok {
+		return nil, nil
+	}
+
+	for _, cells := range rows {
+		if id, err := strconv.ParseUint(cell(cells, col), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	existing := make(map[uint64]bool, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	err := orm.Conn.Model(&Product{}).Where("id IN (?)", ids).Pluck("id", &found).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range found {
+		existing[id] = true
+	}
+
+	return existing, nil
+}
+
+func cell(cells []string, col int) string {
+	if col < len(cells) {
+		return strings.TrimSpace(cells[col])
+	}
+
+	return ""
+}
+
+func emptyRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func splitList(value string) []string {
+	var list []string
+
+	for _, v := range strings.Split(value, catalogListSep) {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// parseCatalogRow checks a row and returns the column of the first problem.
+// Sizes and colors must be letters and digits because carts and orders
+// only accept those.
+func parseCatalogRow(cells []string, columns map[string]int, paths map[string]uint64, existing map[uint64]bool) (*catalogRow, string, error) {
+	var (
+		row catalogRow
+		err error
+	)
+
+	get := func(name string) string {
+		if col, ok := columns[name]; ok {
+			return cell(cells, col)
+		}
+
+		return ""
+	}
+
+	if v := get("id"); v != "" {
+		row.ID, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || !existing[row.ID] {
+			return nil, "id", fmt.Errorf("product %q doesn't exist", v)
+		}
+	}
+
+	p := &row.Product
+
+	p.Name = get("name")
+	if p.Name == "" || len(p.Name) > 256 {
+		return nil, "name", errors.New("name is required and at most 256 bytes")
+	}
+
+	path := strings.Trim(get("category"), catalogPathSep)
+	id, ok := paths[path]
+	if !ok {
+		return nil, "category", fmt.Errorf("category %q doesn't exist", path)
+	}
+	p.Category = id
+
+	p.Price, err = strconv.ParseFloat(get("price"), 64)
+	if err != nil || p.Price < 0 || math.IsNaN(p.Price) || math.IsInf(p.Price, 0) {
+		return nil, "price", errors.New("price must be a number not below 0")
+	}
+
+	if v := get("stock"); v != "" {
+		p.Stock, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, "stock", errors.New("stock must be a whole number not below 0")
+		}
+	}
+
+	p.Size = splitList(get("sizes"))
+	p.Color = splitList(get("colors"))
+
+	for name, list := range map[string][]string{"sizes": p.Size, "colors": p.Color} {
+		if len(list) == 0 {
+			return nil, name, fmt.Errorf("%s are required", name)
+		}
+
+		for _, v := range list {
+			if !alphanumeric(v) || len(v) > 64 {
+				return nil, name, fmt.Errorf("%q must be letters and digits, at most 64", v)
+			}
+		}
+	}
+
+	p.Avatar = get("avatar")
+	p.Images = splitList(get("images"))
+	p.DetailImages = splitList(get("detailimages"))
+
+	if p.Avatar != "" && !imageURL(p.Avatar) {
+		return nil, "avatar", fmt.Errorf("%q is not an http or https URL", p.Avatar)
+	}
+
+	for name, list := range map[string][]string{"images": p.Images, "detailimages": p.DetailImages} {
+		for _, image := range list {
+			if !imageURL(image) {
+				return nil, name, fmt.Errorf("%q is not an http or https URL", image)
+			}
+		}
+	}
+
+	p.Detail = get("detail")
+	if p.Detail == "" || len(p.Detail) > 1024 {
+		return nil, "detail", errors.New("detail is required and at most 1024 bytes")
+	}
+
+	return &row, "", nil
+}
+
+func alphanumeric(s string) bool {
+	for _, ch := range s {
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func imageURL(s string) bool {
+	u, err := url.Parse(s)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && len(s) <= 512
+}
+
+func saveCatalogRow(row *catalogRow) (err error) {
+	if row.ID == 0 {
+		return ProductService.CreateProduct(&row.Product)
+	}
+
+	p := &row.Product
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+
+		if err == nil {
+			invalidateProduct(row.ID)
+		}
+	}()
+
+	updater := map[string]interface{}{
+		"name":     p.Name,
+		"category": p.Category,
+		"price":    p.Price,
+		"stock":    p.Stock,
+		"detail":   p.Detail,
+	}
+
+	err = tx.Model(&Product{}).Where("id = ?", row.ID).Updates(updater).Error
+	if err != nil {
+		return err
+	}
+
+	err = productMedia.Replace(tx, row.ID, p)
+
+	return err
+}
+
+// ExportCatalog hands every product to write as a row in the columns an
+// import reads, starting with the header.
+func (ps *ProductServiceProvider) ExportCatalog(write func(row []string) error) error {
+	var lastKey uint64
+
+	header := make([]string, len(catalogColumns))
+	for i, c := range catalogColumns {
+		header[i] = c.name
+	}
+
+	if err := write(header); err != nil {
+		return err
+	}
+
+	categories, err := categoryPaths()
+	if err != nil {
+		return err
+	}
+
+	names := make(map[uint64]string, len(categories))
+	for path, id := range categories {
+		names[id] = path
+	}
+
+	for {
+		var products []Product
+
+		err = orm.Conn.Where("id > ?", lastKey).Order("id").Limit(exportBatch).Find(&products).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range products {
+			row, err := catalogExportRow(&products[i], names)
+			if err != nil {
+				return err
+			}
+
+			if err = write(row); err != nil {
+				return err
+			}
+		}
+
+		if len(products) < exportBatch {
+			return nil
+		}
+
+		lastKey = products[len(products)-1].ID
+	}
+}
+
+func catalogExportRow(p *Product, categories map[uint64]string) ([]string, error) {
+	var (
+		avatar          string
+		images, details []string
+	)
+
+	media, err := productMedia.Media(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, image := range media.Images {
+		switch image.Class {
+		case general.ProductAvatar:
+			if avatar == "" {
+				avatar = image.Image
+			}
+		case general.ProductImage:
+			images = append(images, image.Image)
+		case general.ProductDetailImage:
+			details = append(details, image.Image)
+		}
+	}
+
+	return []string{
+		strconv.FormatUint(p.ID, 10),
+		p.Name,
+		categories[p.Category],
+		strconv.FormatFloat(p.Price, 'f', -1, 64),
+		strconv.FormatUint(p.Stock, 10),
+		strings.Join(media.Sizes, catalogListSep),
+		strings.Join(media.Colors, catalogListSep),
+		avatar,
+		strings.Join(images, catalogListSep),
+		strings.Join(details, catalogListSep),
+		p.Detail,
+	}, nil
+}
@@ -26,6 +26,7 @@
  * Revision History:
  *     Initial: 2017/07/21        Yang Zhengtian
  *     Modify : 2017/07/21        Li Zebang
+ *     Modify : 2026/10/19
  */
 
 package models
@@ -35,6 +36,8 @@ import (
 
 	"ShopApi/general"
 	"ShopApi/orm"
+	"ShopApi/server/initcache"
+	"ShopApi/utility"
 )
 
 type CategoryServiceProvider struct {
@@ -60,6 +63,7 @@ type GetCategory struct {
 }
 
 type CategoryGet struct {
+	ID   uint64 `json:"id"`
 	Name string `json:"name"`
 }
 
@@ -77,7 +81,12 @@ func (csp *CategoryServiceProvider) CreateCategory(createCategory CreateCategory
 
 	db := orm.Conn
 
-	return db.Create(&category).Error
+	err := db.Create(&category).Error
+	if err == nil {
+		invalidateCategories()
+	}
+
+	return err
 }
 
 func (csp *CategoryServiceProvider) CheckPID(pid uint64) (err error) {
@@ -90,24 +99,35 @@ func (csp *CategoryServiceProvider) CheckPID(pid uint64) (err error) {
 	return db.Where("id =? ", pid).First(&category).Error
 }
 
-func (csp *CategoryServiceProvider) GetCategory() (*[]CategoryGet, error) {
+func (csp *CategoryServiceProvider) categoryList(p *utility.Pagination) (*CategoryPage, error) {
 	var (
-		err          error
-		categories   []Category
-		categoryList []CategoryGet
+		err        error
+		total      uint64
+		lastKey    string
+		categories []Category
+		page       CategoryPage
 	)
 
-	db := orm.Conn
+	query := orm.Conn.Model(&Category{}).Where("status = ?", general.CategoryOnUse)
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, err
+	}
 
-	err = db.Where("status = ?", general.CategoryOnUse).Find(&categories).Error
+	err = paginate(query, "id", p).Find(&categories).Error
 	if err != nil {
 		return nil, err
 	}
 
-	for _, category := range categories {
-		categoryGet := CategoryGet{Name: category.Name}
-		categoryList = append(categoryList, categoryGet)
+	keep, hasMore := p.Trim(len(categories))
+	for _, category := range categories[:keep] {
+		categoryGet := CategoryGet{ID: category.ID, Name: category.Name}
+		page.List = append(page.List, categoryGet)
+		lastKey = uintKey(category.ID)
 	}
 
-	return &categoryList, nil
+	page.Page = p.Info(total, hasMore, lastKey)
+
+	return &page, nil
 }
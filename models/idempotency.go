@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"time"
+
+	"ShopApi/orm"
+)
+
+type IdempotencyServiceProvider struct {
+}
+
+var IdempotencyService *IdempotencyServiceProvider = &IdempotencyServiceProvider{}
+
+// Idempotency remembers the response of a request sent with an
+// Idempotency-Key. Status stays 0 until the first request finishes.
+type Idempotency struct {
+	ID          uint64    `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	Scope       string    `json:"scope"`
+	Key         string    `gorm:"column:idemkey" json:"key"`
+	Fingerprint string    `json:"fingerprint"`
+	Status      int       `json:"status"`
+	ContentType string    `gorm:"column:contenttype" json:"contenttype"`
+	Body        string    `json:"body"`
+	Created     time.Time `json:"created"`
+}
+
+func (Idempotency) TableName() string {
+	return "idempotency"
+}
+
+// Begin claims key for the first request. When another request already
+// claimed it within window, that record is returned with started false. A
+// claim still in progress after lease is taken to be left by a request that
+// panicked or a process that died, and is claimed again.
+func (isp *IdempotencyServiceProvider) Begin(scope, key, fingerprint string, window, lease time.Duration) (*Idempotency, bool, error) {
+	record := Idempotency{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Created:     time.Now(),
+	}
+
+	db := orm.Conn
+
+	for retry := 0; ; retry++ {
+		err := db.Create(&record).Error
+		if err == nil {
+			return &record, true, nil
+		}
+
+		if !isDuplicateEntry(err) {
+			return nil, false, err
+		}
+
+		var existing Idempotency
+		err = db.Where("scope = ? AND idemkey = ?", scope, key).First(&existing).Error
+		if err != nil {
+			return nil, false, err
+		}
+
+		age := time.Since(existing.Created)
+		abandoned := existing.Status == 0 && age >= lease
+		if retry > 0 || (age < window && !abandoned) {
+			return &existing, false, nil
+		}
+
+		// The key expired or its claim was abandoned. The status check keeps
+		// a claim which completed meanwhile, the next Create then finds it.
+		err = db.Where("id = ? AND status = ?", existing.ID, existing.Status).Delete(&Idempotency{}).Error
+		if err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+// Complete stores the response of a claimed key.
+func (isp *IdempotencyServiceProvider) Complete(id uint64, status int, contentType, body string) error {
+	updater := map[string]interface{}{
+		"status":      status,
+		"contenttype": contentType,
+		"body":        body,
+	}
+
+	return orm.Conn.Model(&Idempotency{}).Where("id = ?", id).Updates(updater).Error
+}
+
+// Release gives up a claimed key, so that a retry runs again.
+func (isp *IdempotencyServiceProvider) Release(id uint64) error {
+	return orm.Conn.Where("id = ?", id).Delete(&Idempotency{}).Error
+}
+
+// Purge deletes the keys created before the given time.
+func (isp *IdempotencyServiceProvider) Purge(before time.Time) (int64, error) {
+	db := orm.Conn.Where("created < ?", before).Delete(&Idempotency{})
+
+	return db.RowsAffected, db.Error
+}
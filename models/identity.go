@@ -0,0 +1,319 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+	"ShopApi/utility"
+)
+
+// oauthNamePrefix marks the name and phone of accounts which were created by
+// an OAuth login and have no phone bound.
+const oauthNamePrefix = "oauth-"
+
+var (
+	ErrPhoneAlreadyBound = errors.New("the account already has a phone")
+	ErrNoPhoneBound      = errors.New("the account has no phone")
+	ErrLastSignIn        = errors.New("the phone is the only way to sign in")
+)
+
+type IdentityServiceProvider struct {
+}
+
+var IdentityService *IdentityServiceProvider = &IdentityServiceProvider{}
+
+// UserIdentity links an account of an OAuth provider to a user.
+type UserIdentity struct {
+	ID         uint64    `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	Provider   string    `json:"provider"`
+	ExternalID string    `gorm:"column:externalid" json:"externalid"`
+	UserID     uint64    `gorm:"column:userid" json:"userid"`
+	Nickname   string    `json:"nickname"`
+	Avatar     string    `json:"avatar"`
+	Created    time.Time `json:"created"`
+}
+
+type OAuthLogin struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type OAuthLoginResult struct {
+	UserID     uint64 `json:"userid"`
+	Created    bool   `json:"created"`
+	PhoneBound bool   `json:"phonebound"`
+}
+
+type BindPhone struct {
+	Phone    *string `json:"phone" validate:"required,numeric,len=11"`
+	Password *string `json:"password" validate:"required,alphanum,min=6,max=64"`
+	Code     *string `json:"code" validate:"required,numeric,len=6"`
+}
+
+// BindPhoneCode asks for the SMS code which proves the phone of BindPhone.
+type BindPhoneCode struct {
+	Phone *string `json:"phone" validate:"required,numeric,len=11"`
+}
+
+func (UserIdentity) TableName() string {
+	return "useridentity"
+}
+
+// PhoneBound reports whether a user name is a phone number rather than the
+// placeholder of an OAuth account.
+func PhoneBound(name string) bool {
+	return !strings.HasPrefix(name, oauthNamePrefix)
+}
+
+// SignIn returns the user linked to the external account, registering one
+// on the first login. Suspended and deleted users get ErrUserSuspended and
+// gorm.ErrRecordNotFound like a phone login.
+func (isp *IdentityServiceProvider) SignIn(provider, externalID, nickname, avatar string) (*OAuthLoginResult, error) {
+	var (
+		err      error
+		identity UserIdentity
+		user     User
+	)
+
+	db := orm.Conn
+
+	for retry := 0; ; retry++ {
+		err = db.Where("provider = ? AND externalid = ?", provider, externalID).First(&identity).Error
+		if err != gorm.ErrRecordNotFound {
+			break
+		}
+
+		userID, err := isp.register(provider, externalID, nickname, avatar)
+		if err == nil {
+			return &OAuthLoginResult{UserID: userID, Created: true}, nil
+		}
+
+		// Another login of the same account registered it first.
+		if retry > 0 || !isDuplicateEntry(err) {
+			return nil, err
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("id = ? AND status <> ?", identity.UserID, general.UserDeleted).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == general.UserInactive {
+		return nil, ErrUserSuspended
+	}
+
+	return &OAuthLoginResult{
+		UserID:     user.UserID,
+		PhoneBound: PhoneBound(user.Name),
+	}, nil
+}
+
+// register creates a user without phone or password for the external
+// account. Its name and phone become oauth-<id>, which can't collide with a
+// phone number.
+func (isp *IdentityServiceProvider) register(provider, externalID, nickname, avatar string) (userID uint64, err error) {
+	b := make([]byte, 6)
+	if _, err = rand.Read(b); err != nil {
+		return 0, err
+	}
+	pending := oauthNamePrefix + hex.EncodeToString(b)
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	u := User{
+		Name:    pending,
+		Status:  general.UserActive,
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+
+	err = tx.Create(&u).Error
+	if err != nil {
+		return 0, err
+	}
+
+	name := fmt.Sprintf("%s%d", oauthNamePrefix, u.UserID)
+
+	err = tx.Model(&User{}).Where("id = ?", u.UserID).Update("name", name).Error
+	if err != nil {
+		return 0, err
+	}
+
+	info := UserInfo{
+		UserID:   u.UserID,
+		Phone:    name,
+		Nickname: nickname,
+		Sex:      general.Man,
+	}
+
+	err = tx.Create(&info).Error
+	if err != nil {
+		return 0, err
+	}
+
+	identity := UserIdentity{
+		Provider:   provider,
+		ExternalID: externalID,
+		UserID:     u.UserID,
+		Nickname:   nickname,
+		Avatar:     avatar,
+		Created:    time.Now(),
+	}
+
+	err = tx.Create(&identity).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return u.UserID, nil
+}
+
+// BindPhone gives an OAuth account a phone and password, so it can also log
+// in with them.
+func (isp *IdentityServiceProvider) BindPhone(userID uint64, phone, pass *string) (err error) {
+	var user User
+
+	hashedPass, err := utility.GenerateHash(*pass)
+	if err != nil {
+		return err
+	}
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return err
+	}
+
+	if PhoneBound(user.Name) {
+		err = ErrPhoneAlreadyBound
+		return err
+	}
+
+	updater := map[string]interface{}{
+		"name":     *phone,
+		"password": string(hashedPass),
+		"updated":  time.Now(),
+	}
+
+	err = tx.Model(&User{}).Where("id = ?", userID).Updates(updater).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&UserInfo{}).Where("userid = ?", userID).Update("phone", *phone).Error
+
+	return err
+}
+
+// UnbindPhone turns the account back into an OAuth only account. It is
+// refused when no external account is linked, the user couldn't sign in
+// anymore.
+func (isp *IdentityServiceProvider) UnbindPhone(userID uint64) (err error) {
+	var (
+		user  User
+		count uint64
+	)
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return err
+	}
+
+	if !PhoneBound(user.Name) {
+		err = ErrNoPhoneBound
+		return err
+	}
+
+	err = tx.Model(&UserIdentity{}).Where("userid = ?", userID).Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		err = ErrLastSignIn
+		return err
+	}
+
+	name := fmt.Sprintf("%s%d", oauthNamePrefix, userID)
+
+	updater := map[string]interface{}{
+		"name":     name,
+		"password": "",
+		"updated":  time.Now(),
+	}
+
+	err = tx.Model(&User{}).Where("id = ?", userID).Updates(updater).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&UserInfo{}).Where("userid = ?", userID).Update("phone", name).Error
+
+	return err
+}
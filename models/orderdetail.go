@@ -0,0 +1,257 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"time"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+)
+
+// Actions the customer may take on an order in its current status.
+const (
+	OrderActionPay           = "pay"
+	OrderActionCancel        = "cancel"
+	OrderActionChangeAddress = "changeaddress"
+	OrderActionConfirm       = "confirm"
+	OrderActionReturn        = "return"
+)
+
+var orderStatusNames = map[uint8]string{
+	general.OrderUnfinished: "unpaid",
+	general.OrderSettled:    "paid",
+	general.OrderCanceled:   "canceled",
+	general.OrderShipped:    "shipped",
+	general.OrderReceived:   "received",
+	general.OrderFinished:   "finished",
+	general.OrderReturning:  "returning",
+}
+
+var orderActions = map[uint8][]string{
+	general.OrderUnfinished: {OrderActionPay, OrderActionCancel, OrderActionChangeAddress},
+	general.OrderSettled:    {OrderActionCancel},
+	general.OrderShipped:    {OrderActionConfirm},
+	general.OrderReceived:   {OrderActionReturn},
+	general.OrderFinished:   {OrderActionReturn},
+}
+
+// actionStatus is the status each action moves an order to, changing the
+// address leaves the status alone.
+var actionStatus = map[string]uint8{
+	OrderActionPay:     general.OrderSettled,
+	OrderActionCancel:  general.OrderCanceled,
+	OrderActionConfirm: general.OrderReceived,
+	OrderActionReturn:  general.OrderReturning,
+}
+
+// CustomerCanChange reports whether the customer may move their order from
+// one status to another through one of its actions.
+func CustomerCanChange(from, to uint8) bool {
+	for _, action := range orderActions[from] {
+		if status, ok := actionStatus[action]; ok && status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OrderDetail is an order as shown to the customer.
+type OrderDetail struct {
+	ID         uint64         `json:"id"`
+	OrderNo    string         `json:"orderno"`
+	Status     uint8          `json:"status"`
+	StatusName string         `json:"statusname"`
+	TotalPrice float64        `json:"totalprice"`
+	Freight    float64        `json:"freight"`
+	Remark     string         `json:"remark"`
+	Created    time.Time      `json:"created"`
+	Updated    time.Time      `json:"updated"`
+	Address    *OrderAddress  `json:"address"`
+	Items      []OrderItem    `json:"items"`
+	Payment    OrderPayment   `json:"payment"`
+	Shipment   *OrderShipment `json:"shipment"`
+	History    []OrderHistory `json:"history,omitempty"`
+	Actions    []string       `json:"actions"`
+}
+
+// OrderItem is one product of an order. Subtotal is the unit price times
+// the count, the discount is passed through as the client sent it.
+type OrderItem struct {
+	ProductID uint64  `json:"productid"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Discount  uint8   `json:"discount"`
+	Size      string  `json:"size"`
+	Color     string  `json:"color"`
+	Count     uint64  `json:"count"`
+	Avatar    string  `json:"avatar"`
+	Subtotal  float64 `json:"subtotal"`
+}
+
+type OrderPayment struct {
+	PayWay uint8      `json:"payway"`
+	Paid   *time.Time `json:"paid"`
+}
+
+type OrderShipment struct {
+	Carrier    string     `json:"carrier"`
+	TrackingNo string     `json:"trackingno"`
+	Shipped    *time.Time `json:"shipped"`
+}
+
+func (o *Orders) shipment() *OrderShipment {
+	if o.Shipped == nil && o.TrackingNo == "" {
+		return nil
+	}
+
+	return &OrderShipment{
+		Carrier:    o.Carrier,
+		TrackingNo: o.TrackingNo,
+		Shipped:    o.Shipped,
+	}
+}
+
+// orderDetails builds the details of orders with a fixed number of queries
+// however many orders there are, the history is only loaded when asked.
+func orderDetails(orders []Orders, history bool) ([]OrderDetail, error) {
+	var (
+		err      error
+		products []OrderProduct
+		logs     []OrderHistory
+		missing  []uint64
+		current  []Product
+	)
+
+	details := make([]OrderDetail, len(orders))
+	if len(orders) == 0 {
+		return details, nil
+	}
+
+	ids := make([]uint64, len(orders))
+	index := make(map[uint64]int, len(orders))
+	for i := range orders {
+		ids[i] = orders[i].ID
+		index[orders[i].ID] = i
+
+		details[i] = OrderDetail{
+			ID:         orders[i].ID,
+			OrderNo:    orders[i].OrderNo,
+			Status:     orders[i].Status,
+			StatusName: orderStatusNames[orders[i].Status],
+			TotalPrice: orders[i].TotalPrice,
+			Freight:    orders[i].Freight,
+			Remark:     orders[i].Remark,
+			Created:    orders[i].Created,
+			Updated:    orders[i].Updated,
+			Address:    orders[i].shippingAddress(),
+			Items:      []OrderItem{},
+			Payment:    OrderPayment{PayWay: orders[i].PayWay, Paid: orders[i].Paid},
+			Shipment:   orders[i].shipment(),
+			Actions:    orderActions[orders[i].Status],
+		}
+
+		if details[i].Actions == nil {
+			details[i].Actions = []string{}
+		}
+	}
+
+	err = orm.Conn.Where("orderid IN (?)", ids).Order("id").Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	productIDs := make([]uint64, len(products))
+	for i, p := range products {
+		productIDs[i] = p.ProductID
+		if p.Name == "" {
+			missing = append(missing, p.ProductID)
+		}
+	}
+
+	// Orders placed before names and prices were kept fall back to the
+	// product as it is now.
+	names := make(map[uint64]Product)
+	if len(missing) > 0 {
+		err = orm.Conn.Where("id IN (?)", missing).Find(&current).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range current {
+			names[p.ID] = p
+		}
+	}
+
+	avatars, err := LoadAvatars(productIDs, general.ProductAvatar)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range products {
+		item := OrderItem{
+			ProductID: p.ProductID,
+			Name:      p.Name,
+			Price:     p.Price,
+			Discount:  p.Discount,
+			Size:      p.Size,
+			Color:     p.Color,
+			Count:     p.Count,
+			Avatar:    avatars[p.ProductID],
+		}
+
+		if item.Name == "" {
+			item.Name = names[p.ProductID].Name
+			item.Price = names[p.ProductID].Price
+		}
+
+		item.Subtotal = item.Price * float64(item.Count)
+
+		i := index[p.OrderID]
+		details[i].Items = append(details[i].Items, item)
+	}
+
+	if !history {
+		return details, nil
+	}
+
+	err = orm.Conn.Where("orderid IN (?)", ids).Order("id").Find(&logs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range logs {
+		i := index[l.OrderID]
+		details[i].History = append(details[i].History, l)
+	}
+
+	return details, nil
+}
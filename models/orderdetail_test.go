@@ -0,0 +1,78 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"testing"
+
+	"ShopApi/general"
+)
+
+func TestCustomerCanChange(t *testing.T) {
+	cases := []struct {
+		from, to uint8
+		want     bool
+	}{
+		{general.OrderUnfinished, general.OrderSettled, true},
+		{general.OrderUnfinished, general.OrderCanceled, true},
+		{general.OrderUnfinished, general.OrderShipped, false},
+		{general.OrderSettled, general.OrderCanceled, true},
+		{general.OrderSettled, general.OrderShipped, false},
+		{general.OrderShipped, general.OrderReceived, true},
+		{general.OrderShipped, general.OrderCanceled, false},
+		{general.OrderReceived, general.OrderReturning, true},
+		{general.OrderReceived, general.OrderFinished, false},
+		{general.OrderFinished, general.OrderReturning, true},
+		{general.OrderCanceled, general.OrderSettled, false},
+		{general.OrderReturning, general.OrderCanceled, false},
+	}
+
+	for _, c := range cases {
+		if got := CustomerCanChange(c.from, c.to); got != c.want {
+			t.Errorf("CustomerCanChange(%d, %d) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+// Every action a customer takes has to be a move staff could make too.
+func TestCustomerChangesAreTransitions(t *testing.T) {
+	for from, actions := range orderActions {
+		for _, action := range actions {
+			to, ok := actionStatus[action]
+			if !ok {
+				continue
+			}
+
+			if !CanTransition(from, to) {
+				t.Errorf("action %q moves status %d to %d, which is not a transition", action, from, to)
+			}
+		}
+	}
+}
@@ -28,18 +28,35 @@
  *	   Modify : 2017/07/21		 Ai Hao
  *	   Modify : 2017/07/21		 Zhang Zizhao
  *     Modify : 2017/07/21       Ma Chao
+ *     Modify : 2026/10/19
  */
 
 package models
 
 import (
-	"fmt"
+	"errors"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+
 	"ShopApi/general"
 	"ShopApi/orm"
+	"ShopApi/utility"
+)
 
-	"gopkg.in/mgo.v2/bson"
+var (
+	ErrOrderAddressNotFound = errors.New("address doesn't exist")
+	ErrOrderCartNotFound    = errors.New("ordered product isn't in the cart")
+	ErrOrderNotEditable     = errors.New("order can't be changed any more")
+	ErrOrderRef             = errors.New("orderid or a valid orderno is required")
+)
+
+// orderNoAttempts is how often CreateOrder draws a new order number when
+// the one it drew is taken.
+const (
+	orderNoAttempts     = 3
+	mysqlDuplicateEntry = 1062
 )
 
 type OrderServiceProvider struct {
@@ -47,48 +64,79 @@ type OrderServiceProvider struct {
 
 var OrderService *OrderServiceProvider = &OrderServiceProvider{}
 
+// Orders keeps a copy of the shipping address taken when the order was
+// placed, later edits of the address don't change where the order goes.
 type Orders struct {
-	ID         uint64    `sql:"auto_increment;primary_key" json:"id"`
-	UserID     uint64    `gorm:"column:userid" json:"userid"`
-	AddressID  string    `gorm:"column:addressid" json:"addressid"`
-	TotalPrice float64   `gorm:"column:totalprice" json:"totalprice"`
-	PayWay     uint8     `gorm:"column:payway" json:"payway"`
-	Freight    float64   `json:"freight"`
-	Remark     string    `json:"remark"`
-	Status     uint8     `json:"status"`
-	Created    time.Time `json:"created"`
-	Updated    time.Time `json:"updated"`
+	ID         uint64     `sql:"auto_increment;primary_key" json:"id"`
+	OrderNo    string     `gorm:"column:orderno" json:"orderno"`
+	UserID     uint64     `gorm:"column:userid" json:"userid"`
+	AddressID  string     `gorm:"column:addressid" json:"addressid"`
+	Receiver   string     `json:"receiver"`
+	Phone      string     `json:"phone"`
+	Area       string     `json:"area"`
+	Province   string     `json:"province"`
+	City       string     `json:"city"`
+	District   string     `json:"district"`
+	Address    string     `json:"address"`
+	TotalPrice float64    `gorm:"column:totalprice" json:"totalprice"`
+	PayWay     uint8      `gorm:"column:payway" json:"payway"`
+	Freight    float64    `json:"freight"`
+	Remark     string     `json:"remark"`
+	Status     uint8      `json:"status"`
+	Paid       *time.Time `json:"paid"`
+	Carrier    string     `json:"carrier"`
+	TrackingNo string     `gorm:"column:trackingno" json:"trackingno"`
+	Shipped    *time.Time `json:"shipped"`
+	Created    time.Time  `json:"created"`
+	Updated    time.Time  `json:"updated"`
 }
 
+// OrderProduct keeps the name and price of the product when it was bought.
 type OrderProduct struct {
-	ID        uint64 `sql:"auto_increment;primary_key" json:"id"`
-	OrderID   uint64 `gorm:"column:orderid" json:"orderid"`
-	ProductID uint64 `gorm:"column:productid" json:"productid"`
-	Discount  uint8  `json:"discount"`
-	Size      string `json:"size"`
-	Count     uint64 `json:"count"`
-	Color     string `json:"color"`
+	ID        uint64  `sql:"auto_increment;primary_key" json:"id"`
+	OrderID   uint64  `gorm:"column:orderid" json:"orderid"`
+	ProductID uint64  `gorm:"column:productid" json:"productid"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Discount  uint8   `json:"discount"`
+	Size      string  `json:"size"`
+	Count     uint64  `json:"count"`
+	Color     string  `json:"color"`
+}
+
+// OrderHistory is one status an order went through.
+type OrderHistory struct {
+	ID      uint64    `sql:"auto_increment;primary_key" json:"-"`
+	OrderID uint64    `gorm:"column:orderid" json:"-"`
+	Status  uint8     `json:"status"`
+	Created time.Time `json:"created"`
 }
 
-type OrmOrders struct {
-	TotalPrice float64   `json:"totalprice" `
-	Freight    float64   `json:"freight" `
-	Discount   uint8     `json:"discount" `
-	Name       string    `json:"name" validate:"required,alphaunicode,min=2,max=18"`
-	Size       string    `json:"size" validate:"required,alphanum"`
-	Count      uint64    `json:"count"`
-	Color      string    `json:"color" validate:"required,alphanum"`
-	Status     uint8     `json:"status"`
-	PayWay     uint8     `json:"payway"`
-	AddressID  string    `json:"addressid" validate:"required,numeric"`
-	ProductID  uint64    `json:"productid"`
-	Remark     string    `json:"remark" validate:"required,alphanum"`
-	Created    time.Time `json:"created"`
-	Avatar     string    `json:"avatar"`
+// OrderAddress is the shipping address an order was placed with.
+type OrderAddress struct {
+	AddressID string `json:"addressid"`
+	Receiver  string `json:"receiver"`
+	Phone     string `json:"phone"`
+	Area      string `json:"area"`
+	Province  string `json:"province"`
+	City      string `json:"city"`
+	District  string `json:"district"`
+	Address   string `json:"detailAdress"`
+}
+
+// OrderRef picks an order either by its ID or by its order number.
+type OrderRef struct {
+	OrderID uint64 `json:"orderid"`
+	OrderNo string `json:"orderno"`
+}
+
+type ChangeOrderAddress struct {
+	OrderRef
+	AddressID uint64 `json:"addressid,string" validate:"required"`
 }
 
 type CreateOrder struct {
-	AddressID    string  `json:"addressid" validate:"required"`
+	AddressID    uint64  `json:"addressid,string" validate:"required"`
 	TotalPrice   float64 `json:"totalprice"`
 	Freight      float64 `json:"freight"`
 	Remark       string  `json:"remark"`
@@ -105,27 +153,21 @@ type OrderPro struct {
 	Color     string `json:"color" validate:"required,alphanum"`
 }
 
+// GetOrders lists orders, OrderNo may be a prefix such as the date.
 type GetOrders struct {
-	UserID   uint64 `json:"userid"`
-	Status   uint8  `json:"status"`
-	Page     uint64 `json:"page"`
-	PageSize uint64 `json:"pagesize"`
+	UserID  uint64 `json:"userid"`
+	Status  uint8  `json:"status"`
+	OrderNo string `json:"orderno" validate:"omitempty,numeric,max=18"`
+	utility.Pagination
 }
 
 type GetOne struct {
-	ID uint64 `json:"orderid" `
-}
-
-type OrdersGet struct {
-	TotalPrice float64 `json:"totalprice"`
-	Freight    float64 `json:"freight"`
-	Remark     string  `json:"remark"`
-	Status     uint8   `json:"status"`
+	OrderRef
 }
 
 type ChangeStatus struct {
-	Status  uint8  `json:"status"`
-	OrderID uint64 `json:"orderid"`
+	Status uint8 `json:"status"`
+	OrderRef
 }
 
 func (Orders) TableName() string {
@@ -136,52 +178,171 @@ func (OrderProduct) TableName() string {
 	return "orderproduct"
 }
 
-var CartsDeleted CartsDelete
+func (OrderHistory) TableName() string {
+	return "orderhistory"
+}
 
-func (osp *OrderServiceProvider) CreateOrder(UserID uint64, ord CreateOrder) error {
-	var (
-		err    error
-		orders Orders
-	)
+// Validate checks that the reference names an order, a mistyped order
+// number is rejected by its check digit.
+func (r *OrderRef) Validate() error {
+	if r.OrderNo != "" {
+		if !utility.ValidOrderNo(r.OrderNo) {
+			return ErrOrderRef
+		}
+
+		return nil
+	}
+
+	if r.OrderID == 0 {
+		return ErrOrderRef
+	}
+
+	return nil
+}
+
+func (r *OrderRef) where(query *gorm.DB) *gorm.DB {
+	if r.OrderNo != "" {
+		return query.Where("orderno = ?", r.OrderNo)
+	}
+
+	return query.Where("id = ?", r.OrderID)
+}
+
+// ValidOrderStatus reports whether status is one an order can be in.
+func ValidOrderStatus(status uint8) bool {
+	_, ok := orderStatusNames[status]
+	return ok
+}
+
+// snapshot copies the address of the user into the order, the address
+// must belong to the user.
+func (o *Orders) snapshot(tx *gorm.DB, userID, addressID uint64) error {
+	var address Address
+
+	err := tx.Where("id = ? AND userid = ?", addressID, userID).First(&address).Error
+	if err == gorm.ErrRecordNotFound {
+		return ErrOrderAddressNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	o.AddressID = uintKey(address.ID)
+	o.Receiver = address.Name
+	o.Phone = address.Phone
+	o.Area = address.Area
+	o.Province = address.Province
+	o.City = address.City
+	o.District = address.District
+	o.Address = address.Address
+
+	return nil
+}
+
+func (o *Orders) shippingAddress() *OrderAddress {
+	return &OrderAddress{
+		AddressID: o.AddressID,
+		Receiver:  o.Receiver,
+		Phone:     o.Phone,
+		Area:      o.Area,
+		Province:  o.Province,
+		City:      o.City,
+		District:  o.District,
+		Address:   o.Address,
+	}
+}
+
+// isDuplicateEntry reports whether err is MySQL refusing a duplicate key.
+func isDuplicateEntry(err error) bool {
+	e, ok := err.(*mysql.MySQLError)
+
+	return ok && e.Number == mysqlDuplicateEntry
+}
+
+// createOrder inserts order under a fresh order number, drawing another one
+// if the number is already taken.
+func createOrder(tx *gorm.DB, order *Orders) (err error) {
+	for i := 0; i < orderNoAttempts; i++ {
+		order.OrderNo, err = utility.OrderNo(order.Created)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(order).Error
+		if !isDuplicateEntry(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// CreateOrder stores the order with its products and returns the new order
+// together with the cart items it bought.
+func (osp *OrderServiceProvider) CreateOrder(UserID uint64, ord CreateOrder) (created *Orders, bought *CartsDelete, err error) {
+	var products []Product
 
 	db := orm.Conn
 
+	now := time.Now()
 	order := Orders{
 		UserID:     UserID,
-		AddressID:  ord.AddressID,
 		TotalPrice: ord.TotalPrice,
 		Freight:    ord.Freight,
 		Remark:     ord.Remark,
 		Status:     general.OrderUnfinished,
 		PayWay:     ord.PayWay,
-		Created:    time.Now(),
-		Updated:    time.Now(),
+		Created:    now,
+		Updated:    now,
 	}
 
 	tx := db.Begin()
 
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
 	}()
 
-	err = tx.Create(&order).Error
+	err = order.snapshot(tx, UserID, ord.AddressID)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	err = tx.Where("userid = ? AND totalprice = ? AND addressid = ?", UserID, order.TotalPrice, order.AddressID).First(&orders).Error
+	err = createOrder(tx, &order)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	err = tx.Create(&OrderHistory{OrderID: order.ID, Status: order.Status, Created: now}).Error
+	if err != nil {
+		return nil, nil, err
 	}
 
+	ids := make([]uint64, len(ord.OrderProduct))
+	for i, value := range ord.OrderProduct {
+		ids[i] = value.ProductID
+	}
+
+	err = tx.Where("id IN (?)", ids).Find(&products).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[uint64]Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	bought = &CartsDelete{}
 	for _, value := range ord.OrderProduct {
 		OrderProduct := OrderProduct{
-			OrderID:   orders.ID,
+			OrderID:   order.ID,
 			ProductID: value.ProductID,
+			Name:      byID[value.ProductID].Name,
+			Price:     byID[value.ProductID].Price,
 			Discount:  value.Discount,
 			Size:      value.Size,
 			Count:     value.Count,
@@ -190,7 +351,7 @@ func (osp *OrderServiceProvider) CreateOrder(UserID uint64, ord CreateOrder) err
 
 		err = tx.Create(&OrderProduct).Error
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		add1 := CartDelete{
@@ -198,124 +359,177 @@ func (osp *OrderServiceProvider) CreateOrder(UserID uint64, ord CreateOrder) err
 			Size:      OrderProduct.Size,
 			Color:     OrderProduct.Color,
 		}
-		CartsDeleted.Data = append(CartsDeleted.Data, add1)
+		bought.Data = append(bought.Data, add1)
 	}
 
-	return err
+	err = cartsOrdered(tx, bought, UserID, order.ID)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	return &order, bought, nil
 }
 
-func (osp *OrderServiceProvider) GetOrders(getOrders *GetOrders, pageStart uint64) (*[]OrdersGet, error) {
+func (osp *OrderServiceProvider) GetOrders(getOrders *GetOrders) ([]OrderDetail, *general.PageInfo, error) {
 	var (
-		sql        string
-		order      Orders
-		orderGet   OrdersGet
-		ordersList []OrdersGet
+		err     error
+		total   uint64
+		lastKey string
+		orders  []Orders
 	)
 
-	db := orm.Conn
+	query := orm.Conn.Model(&Orders{}).Where("userid = ?", getOrders.UserID)
+	if getOrders.Status != general.OrderGetAll {
+		query = query.Where("status = ?", getOrders.Status)
+	}
 
-	if getOrders.Status == general.OrderUnfinished || getOrders.Status == general.OrderFinished || getOrders.Status == general.OrderCanceled {
-		sql = fmt.Sprintf("SELECT * FROM orders WHERE userid = %d AND status = %d LIMIT %d, %d LOCK IN SHARE MODE", getOrders.UserID, getOrders.Status, pageStart, getOrders.PageSize)
-	} else {
-		sql = fmt.Sprintf("SELECT * FROM orders WHERE userid = %d LIMIT %d, %d LOCK IN SHARE MODE", getOrders.UserID, pageStart, getOrders.PageSize)
+	if getOrders.OrderNo != "" {
+		query = query.Where("orderno LIKE ?", getOrders.OrderNo+"%")
 	}
 
-	rows, err := db.Raw(sql).Rows()
-	defer rows.Close()
+	err = query.Count(&total).Error
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	for rows.Next() {
-		db.ScanRows(rows, &order)
-		fmt.Println(order)
-		orderGet = OrdersGet{
-			TotalPrice: order.TotalPrice,
-			Freight:    order.Freight,
-			Remark:     order.Remark,
-			Status:     order.Status,
-		}
-		ordersList = append(ordersList, orderGet)
+	err = paginate(query, "id", &getOrders.Pagination).Find(&orders).Error
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return &ordersList, nil
+	keep, hasMore := getOrders.Trim(len(orders))
+	orders = orders[:keep]
+	if keep > 0 {
+		lastKey = uintKey(orders[keep-1].ID)
+	}
+
+	details, err := orderDetails(orders, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return details, getOrders.Info(total, hasMore, lastKey), nil
 }
 
-func (osp *OrderServiceProvider) GetOneOrder(userID uint64, ID uint64) ([]OrmOrders, error) {
-	var (
-		err           error
-		order         Orders
-		OrderProduct  []OrderProduct
-		getOrder      []OrmOrders
-		productAvatar ProductImages
-	)
+// GetOneOrder returns an order of the user with its status history.
+func (osp *OrderServiceProvider) GetOneOrder(userID uint64, ref *OrderRef) (*OrderDetail, error) {
+	var order Orders
 
-	db := orm.Conn
-	tx := db.Begin()
+	err := ref.where(orm.Conn).Where("userid = ?", userID).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := orderDetails([]Orders{order}, true)
+	if err != nil {
+		return nil, err
+	}
 
+	return &details[0], nil
+}
+
+// ChangeStatus moves an order of the user to status and records it in the
+// history. Customers only take the actions of the order, staff change
+// orders with AdminChangeStatus.
+func (osp *OrderServiceProvider) ChangeStatus(userID uint64, ref *OrderRef, status uint8) (err error) {
+	var order Orders
+
+	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
 	}()
 
-	err = tx.Where("id = ? AND userid = ?", ID, userID).First(&order).Error
+	err = ref.where(tx.Set("gorm:query_option", "FOR UPDATE")).Where("userid = ?", userID).First(&order).Error
 	if err != nil {
-		return getOrder, err
+		return err
+	}
+
+	if !CustomerCanChange(order.Status, status) {
+		err = ErrOrderTransition
+		return err
+	}
+
+	err = setStatus(tx, order.ID, status, nil)
+
+	return err
+}
+
+// setStatus moves an order to status inside tx, stamping when it was paid
+// or shipped, and records it in the history.
+func setStatus(tx *gorm.DB, orderID uint64, status uint8, ship *OrderShipment) error {
+	now := time.Now()
+
+	updater := map[string]interface{}{
+		"status":  status,
+		"updated": now,
+	}
+
+	switch status {
+	case general.OrderSettled:
+		updater["paid"] = now
+	case general.OrderShipped:
+		updater["shipped"] = now
 	}
 
-	add1 := OrmOrders{
-		TotalPrice: order.TotalPrice,
-		Freight:    order.Freight,
-		Status:     order.Status,
-		Created:    order.Created,
-		PayWay:     order.PayWay,
-		AddressID:  order.AddressID,
-		Remark:     order.Remark,
+	if ship != nil {
+		updater["carrier"] = ship.Carrier
+		updater["trackingno"] = ship.TrackingNo
 	}
-	getOrder = append(getOrder, add1)
 
-	err = tx.Where("orderid = ?", order.ID).Find(&OrderProduct).Error
+	err := tx.Model(&Orders{}).Where("id = ?", orderID).Updates(updater).Error
 	if err != nil {
-		return getOrder, err
+		return err
 	}
 
-	for _, v := range OrderProduct {
-		add1 := OrmOrders{
-			Discount:  v.Discount,
-			Count:     v.Count,
-			Size:      v.Size,
-			Color:     v.Color,
-			ProductID: v.ProductID,
-		}
-		getOrder = append(getOrder, add1)
+	return tx.Create(&OrderHistory{OrderID: orderID, Status: status, Created: now}).Error
+}
 
-		collection := orm.MDSession.DB(orm.MD).C("productimage")
-		orm.MDSession.Refresh()
+// ChangeAddress ships an order of the user to another of their addresses,
+// only while the order is neither paid nor finished.
+func (osp *OrderServiceProvider) ChangeAddress(userID uint64, change *ChangeOrderAddress) (err error) {
+	var order Orders
 
-		err = collection.Find(bson.M{"productid": add1.ProductID, "class": general.ProductAvatar}).One(&productAvatar)
+	tx := orm.Conn.Begin()
+	defer func() {
 		if err != nil {
-			return nil, err
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
 		}
+	}()
+
+	err = change.where(tx.Set("gorm:query_option", "FOR UPDATE")).Where("userid = ?", userID).First(&order).Error
+	if err != nil {
+		return err
+	}
 
-		add1.Avatar = productAvatar.Image
-		getOrder = append(getOrder, add1)
+	if order.Status != general.OrderUnfinished {
+		err = ErrOrderNotEditable
+		return err
 	}
 
-	return getOrder, nil
-}
+	err = order.snapshot(tx, userID, change.AddressID)
+	if err != nil {
+		return err
+	}
 
-func (osp *OrderServiceProvider) ChangeStatus(OrderID uint64, status uint8) error {
-	cha := Orders{
-		Status: status,
+	updater := map[string]interface{}{
+		"addressid": order.AddressID,
+		"receiver":  order.Receiver,
+		"phone":     order.Phone,
+		"area":      order.Area,
+		"province":  order.Province,
+		"city":      order.City,
+		"district":  order.District,
+		"address":   order.Address,
+		"updated":   time.Now(),
 	}
 
-	updater := map[string]interface{}{"status": status}
-	db := orm.Conn
+	err = tx.Model(&Orders{}).Where("id = ?", order.ID).Updates(updater).Error
 
-	err := db.Model(&cha).Where("id = ?", OrderID).Update(updater).Limit(1).Error
 	return err
 }
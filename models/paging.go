@@ -0,0 +1,63 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+
+	"ShopApi/general"
+	"ShopApi/utility"
+)
+
+// ProductPage and CategoryPage are cached together with their paging info.
+type ProductPage struct {
+	List []ProductList     `json:"list"`
+	Page *general.PageInfo `json:"page"`
+}
+
+type CategoryPage struct {
+	List []CategoryGet     `json:"list"`
+	Page *general.PageInfo `json:"page"`
+}
+
+// paginate restricts query to the rows of page p, ordered by the key column.
+func paginate(query *gorm.DB, key string, p *utility.Pagination) *gorm.DB {
+	if after := p.After(); after != "" {
+		query = query.Where(key+" > ?", after)
+	}
+
+	return query.Order(key).Offset(int(p.Offset())).Limit(int(p.Fetch()))
+}
+
+func uintKey(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
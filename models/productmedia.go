@@ -0,0 +1,408 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/jinzhu/gorm"
+	"gopkg.in/mgo.v2/bson"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+)
+
+const (
+	// Product media store
+	MediaStoreMongo = "mongo"
+	MediaStoreMysql = "mysql"
+
+	// Mongo collections
+	collectionProductImage = "productimage"
+	collectionProductSize  = "productsize"
+	collectionProductColor = "productcolors"
+)
+
+var (
+	ErrUnknownMediaStore = errors.New("unknown product media store")
+
+	productMedia ProductMediaStore = &mongoMediaStore{}
+
+	avatarPlaceholder atomic.Value
+)
+
+// ProductMediaStore keeps the images, sizes and colors of a product.
+type ProductMediaStore interface {
+	// Save writes the media of a new product, tx is the transaction which
+	// creates the product row.
+	Save(tx *gorm.DB, productID uint64, create *CreateProduct) error
+
+	// Avatars returns the first image of the given class for each product,
+	// products without such an image are absent from the result.
+	Avatars(productIDs []uint64, class uint8) (map[uint64]string, error)
+
+	// Media returns all images, sizes and colors of a product.
+	Media(productID uint64) (*ProductMedia, error)
+
+	// Replace swaps all media of an existing product for the new ones, tx
+	// is the transaction which updates the product row.
+	Replace(tx *gorm.DB, productID uint64, create *CreateProduct) error
+}
+
+type ProductMedia struct {
+	Images []ProductImages
+	Sizes  []string
+	Colors []string
+}
+
+// ProductImageRow, ProductSizeRow and ProductColorRow are the relational
+// counterparts of the Mongo documents.
+type ProductImageRow struct {
+	ID        uint64 `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	ProductID uint64 `gorm:"column:productid" json:"productid"`
+	Class     uint8  `json:"class"`
+	Image     string `json:"image"`
+}
+
+type ProductSizeRow struct {
+	ID        uint64 `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	ProductID uint64 `gorm:"column:productid" json:"productid"`
+	Size      string `json:"size"`
+}
+
+type ProductColorRow struct {
+	ID        uint64 `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	ProductID uint64 `gorm:"column:productid" json:"productid"`
+	Color     string `json:"color"`
+}
+
+func (ProductImageRow) TableName() string {
+	return "productimage"
+}
+
+func (ProductSizeRow) TableName() string {
+	return "productsize"
+}
+
+func (ProductColorRow) TableName() string {
+	return "productcolor"
+}
+
+// UseProductMediaStore selects where product media is kept.
+func UseProductMediaStore(name string) error {
+	switch name {
+	case "", MediaStoreMongo:
+		productMedia = &mongoMediaStore{}
+	case MediaStoreMysql:
+		productMedia = &mysqlMediaStore{}
+	default:
+		return ErrUnknownMediaStore
+	}
+
+	return nil
+}
+
+// SetAvatarPlaceholder sets the image returned for products which have no
+// avatar.
+func SetAvatarPlaceholder(image string) {
+	avatarPlaceholder.Store(image)
+}
+
+// LoadAvatars fetches the avatars of a page of products with one query.
+// Products without an image of the class get the placeholder instead of
+// failing the whole page.
+func LoadAvatars(productIDs []uint64, class uint8) (map[uint64]string, error) {
+	avatars := make(map[uint64]string, len(productIDs))
+	if len(productIDs) == 0 {
+		return avatars, nil
+	}
+
+	found, err := productMedia.Avatars(productIDs, class)
+	if err != nil {
+		return avatars, err
+	}
+
+	placeholder, _ := avatarPlaceholder.Load().(string)
+
+	for _, id := range productIDs {
+		if image, ok := found[id]; ok {
+			avatars[id] = image
+		} else {
+			avatars[id] = placeholder
+		}
+	}
+
+	return avatars, nil
+}
+
+func productImages(productID uint64, create *CreateProduct) []ProductImages {
+	images := []ProductImages{{
+		Class:     general.ProductAvatar,
+		ProductID: productID,
+		Image:     create.Avatar,
+	}}
+
+	for _, img := range create.Images {
+		images = append(images, ProductImages{
+			Class:     general.ProductImage,
+			ProductID: productID,
+			Image:     img,
+		})
+	}
+
+	for _, img := range create.DetailImages {
+		images = append(images, ProductImages{
+			Class:     general.ProductDetailImage,
+			ProductID: productID,
+			Image:     img,
+		})
+	}
+
+	return images
+}
+
+type mongoMediaStore struct{}
+
+// Save writes to Mongo outside of tx, a failure still rolls back the
+// product row but documents inserted before the failure are left behind.
+func (ms *mongoMediaStore) Save(tx *gorm.DB, productID uint64, create *CreateProduct) error {
+	var err error
+
+	orm.MDSession.Refresh()
+	db := orm.MDSession.DB(orm.MD)
+
+	for _, img := range productImages(productID, create) {
+		err = db.C(collectionProductImage).Insert(img)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, si := range create.Size {
+		err = db.C(collectionProductSize).Insert(ProductSize{ProductID: productID, Size: si})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, co := range create.Color {
+		err = db.C(collectionProductColor).Insert(ProductColor{ProductID: productID, Color: co})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ms *mongoMediaStore) Avatars(productIDs []uint64, class uint8) (map[uint64]string, error) {
+	var images []ProductImages
+
+	avatars := make(map[uint64]string, len(productIDs))
+
+	collection := orm.MDSession.DB(orm.MD).C(collectionProductImage)
+	orm.MDSession.Refresh()
+
+	query := bson.M{"productid": bson.M{"$in": productIDs}, "class": class}
+	err := collection.Find(query).Sort("_id").All(&images)
+	if err != nil {
+		return avatars, err
+	}
+
+	for _, image := range images {
+		if _, ok := avatars[image.ProductID]; !ok {
+			avatars[image.ProductID] = image.Image
+		}
+	}
+
+	return avatars, nil
+}
+
+func (ms *mongoMediaStore) Media(productID uint64) (*ProductMedia, error) {
+	var (
+		err    error
+		media  ProductMedia
+		sizes  []ProductSize
+		colors []ProductColor
+	)
+
+	orm.MDSession.Refresh()
+	db := orm.MDSession.DB(orm.MD)
+
+	err = db.C(collectionProductImage).Find(bson.M{"productid": productID}).All(&media.Images)
+	if err != nil {
+		return &media, err
+	}
+
+	err = db.C(collectionProductSize).Find(bson.M{"productid": productID}).All(&sizes)
+	if err != nil {
+		return &media, err
+	}
+
+	for _, size := range sizes {
+		media.Sizes = append(media.Sizes, size.Size)
+	}
+
+	err = db.C(collectionProductColor).Find(bson.M{"productid": productID}).All(&colors)
+	if err != nil {
+		return &media, err
+	}
+
+	for _, color := range colors {
+		media.Colors = append(media.Colors, color.Color)
+	}
+
+	return &media, nil
+}
+
+// Replace has the same caveat as Save, the old documents are already gone
+// when a failure rolls back the product row.
+func (ms *mongoMediaStore) Replace(tx *gorm.DB, productID uint64, create *CreateProduct) error {
+	orm.MDSession.Refresh()
+	db := orm.MDSession.DB(orm.MD)
+
+	for _, name := range []string{collectionProductImage, collectionProductSize, collectionProductColor} {
+		if _, err := db.C(name).RemoveAll(bson.M{"productid": productID}); err != nil {
+			return err
+		}
+	}
+
+	return ms.Save(tx, productID, create)
+}
+
+type mysqlMediaStore struct{}
+
+func (ss *mysqlMediaStore) Save(tx *gorm.DB, productID uint64, create *CreateProduct) error {
+	var err error
+
+	for _, img := range productImages(productID, create) {
+		row := ProductImageRow{
+			ProductID: productID,
+			Class:     img.Class,
+			Image:     img.Image,
+		}
+
+		err = tx.Create(&row).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, si := range create.Size {
+		err = tx.Create(&ProductSizeRow{ProductID: productID, Size: si}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, co := range create.Color {
+		err = tx.Create(&ProductColorRow{ProductID: productID, Color: co}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ss *mysqlMediaStore) Avatars(productIDs []uint64, class uint8) (map[uint64]string, error) {
+	var images []ProductImageRow
+
+	avatars := make(map[uint64]string, len(productIDs))
+
+	err := orm.Conn.Where("productid IN (?) AND class = ?", productIDs, class).Order("id").Find(&images).Error
+	if err != nil {
+		return avatars, err
+	}
+
+	for _, image := range images {
+		if _, ok := avatars[image.ProductID]; !ok {
+			avatars[image.ProductID] = image.Image
+		}
+	}
+
+	return avatars, nil
+}
+
+func (ss *mysqlMediaStore) Media(productID uint64) (*ProductMedia, error) {
+	var (
+		err    error
+		media  ProductMedia
+		images []ProductImageRow
+		sizes  []ProductSizeRow
+		colors []ProductColorRow
+	)
+
+	db := orm.Conn
+
+	err = db.Where("productid = ?", productID).Order("id").Find(&images).Error
+	if err != nil {
+		return &media, err
+	}
+
+	for _, image := range images {
+		media.Images = append(media.Images, ProductImages{
+			Class:     image.Class,
+			ProductID: image.ProductID,
+			Image:     image.Image,
+		})
+	}
+
+	err = db.Where("productid = ?", productID).Order("id").Find(&sizes).Error
+	if err != nil {
+		return &media, err
+	}
+
+	for _, size := range sizes {
+		media.Sizes = append(media.Sizes, size.Size)
+	}
+
+	err = db.Where("productid = ?", productID).Order("id").Find(&colors).Error
+	if err != nil {
+		return &media, err
+	}
+
+	for _, color := range colors {
+		media.Colors = append(media.Colors, color.Color)
+	}
+
+	return &media, nil
+}
+
+func (ss *mysqlMediaStore) Replace(tx *gorm.DB, productID uint64, create *CreateProduct) error {
+	for _, row := range []interface{}{&ProductImageRow{}, &ProductSizeRow{}, &ProductColorRow{}} {
+		if err := tx.Where("productid = ?", productID).Delete(row).Error; err != nil {
+			return err
+		}
+	}
+
+	return ss.Save(tx, productID, create)
+}
@@ -0,0 +1,128 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+// countingMediaStore answers from memory and counts the calls.
+type countingMediaStore struct {
+	images  map[uint64]string
+	queries int64
+}
+
+func newCountingMediaStore(products int) *countingMediaStore {
+	store := &countingMediaStore{images: make(map[uint64]string, products)}
+
+	// every tenth product has no avatar and gets the placeholder
+	for id := uint64(1); id <= uint64(products); id++ {
+		if id%10 != 0 {
+			store.images[id] = fmt.Sprintf("https://img.example.com/%d.jpg", id)
+		}
+	}
+
+	return store
+}
+
+func (cs *countingMediaStore) Save(tx *gorm.DB, productID uint64, create *CreateProduct) error {
+	return nil
+}
+
+func (cs *countingMediaStore) Avatars(productIDs []uint64, class uint8) (map[uint64]string, error) {
+	atomic.AddInt64(&cs.queries, 1)
+
+	avatars := make(map[uint64]string, len(productIDs))
+	for _, id := range productIDs {
+		if image, ok := cs.images[id]; ok {
+			avatars[id] = image
+		}
+	}
+
+	return avatars, nil
+}
+
+func (cs *countingMediaStore) Media(productID uint64) (*ProductMedia, error) {
+	return &ProductMedia{}, nil
+}
+
+func (cs *countingMediaStore) Replace(tx *gorm.DB, productID uint64, create *CreateProduct) error {
+	return nil
+}
+
+func useCountingMediaStore(t *testing.T, products int) ([]uint64, *countingMediaStore) {
+	store := newCountingMediaStore(products)
+
+	saved := productMedia
+	productMedia = store
+	t.Cleanup(func() { productMedia = saved })
+
+	ids := make([]uint64, products)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+
+	return ids, store
+}
+
+func TestLoadAvatars(t *testing.T) {
+	SetAvatarPlaceholder("placeholder.jpg")
+	ids, store := useCountingMediaStore(t, 20)
+
+	avatars, err := LoadAvatars(ids, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if store.queries != 1 {
+		t.Errorf("queries = %d, want 1", store.queries)
+	}
+
+	if len(avatars) != len(ids) {
+		t.Fatalf("got %d avatars, want %d", len(avatars), len(ids))
+	}
+
+	if avatars[3] != "https://img.example.com/3.jpg" {
+		t.Errorf("avatar of 3 = %q", avatars[3])
+	}
+
+	if avatars[10] != "placeholder.jpg" {
+		t.Errorf("avatar of 10 = %q, want the placeholder", avatars[10])
+	}
+
+	empty, err := LoadAvatars(nil, 0)
+	if err != nil || len(empty) != 0 || store.queries != 1 {
+		t.Errorf("empty page: %v, %v, %d queries", empty, err, store.queries)
+	}
+}
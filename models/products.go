@@ -29,16 +29,17 @@
  *     Modify : 2017/08/10         Yu Yi
  *     Modify : 2017/07/21         Ma chao
  *     Modify : 2017/08/10         Li Zebang
+ *     Modify : 2026/10/19
  */
 
 package models
 
 import (
-	"fmt"
 	"time"
 
 	"ShopApi/general"
 	"ShopApi/orm"
+	"ShopApi/utility"
 
 	"gopkg.in/mgo.v2/bson"
 )
@@ -54,6 +55,7 @@ type Product struct {
 	TotalSale uint64    `gorm:"column:totalsale" json:"totalsale"`
 	Category  uint64    `json:"categories"`
 	Price     float64   `json:"price"`
+	Stock     uint64    `json:"stock"`
 	Detail    string    `json:"detail"`
 	Status    uint8     `json:"status"`
 	Created   time.Time `json:"created"`
@@ -85,6 +87,7 @@ type CreateProduct struct {
 	DetailImages []string `json:"detailimages"`
 	Category     uint64   `json:"category"`
 	Price        float64  `json:"price"`
+	Stock        uint64   `json:"stock"`
 	Size         []string `json:"size" validate:"required"`
 	Color        []string `json:"color" validate:"required"`
 	Detail       string   `json:"detail" validate:"required"`
@@ -100,8 +103,7 @@ type ProductList struct {
 
 type ProductCategory struct {
 	Category uint64 `json:"category"`
-	Page     uint64 `json:"page"`
-	PageSize uint64 `json:"pagesize"`
+	utility.Pagination
 }
 
 type ProductID struct {
@@ -115,6 +117,7 @@ type ProductInfo struct {
 	TotalSale    uint64   `json:"totalsale"`
 	Category     uint64   `json:"category"`
 	Price        float64  `json:"price"`
+	Stock        uint64   `json:"stock"`
 	Size         []string `json:"size"`
 	Color        []string `json:"color"`
 	Detail       string   `json:"detail"`
@@ -134,9 +137,8 @@ func (Product) TableName() string {
 	return "product"
 }
 
-func (ps *ProductServiceProvider) CreateProduct(create *CreateProduct) error {
+func (ps *ProductServiceProvider) CreateProduct(create *CreateProduct) (err error) {
 	var (
-		err     error
 		product Product
 	)
 
@@ -144,6 +146,7 @@ func (ps *ProductServiceProvider) CreateProduct(create *CreateProduct) error {
 		Name:     create.Name,
 		Category: create.Category,
 		Price:    create.Price,
+		Stock:    create.Stock,
 		Detail:   create.Detail,
 		Status:   general.ProductOnSale,
 		Created:  time.Now(),
@@ -152,10 +155,14 @@ func (ps *ProductServiceProvider) CreateProduct(create *CreateProduct) error {
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
+
+		if err == nil {
+			invalidateProduct(product.ID)
+		}
 	}()
 
 	err = tx.Create(&product).Error
@@ -163,141 +170,13 @@ func (ps *ProductServiceProvider) CreateProduct(create *CreateProduct) error {
 		return err
 	}
 
-	err = tx.Where("name = ?", create.Name).First(&product).Error
-	if err != nil {
-		return err
-	}
-
-	err = AddProductImage(product.ID, create)
-	if err != nil {
-		return err
-	}
-
-	err = AddProductSize(product.ID, create)
-	if err != nil {
-		return err
-	}
-
-	err = AddProductColor(product.ID, create)
-	if err != nil {
-		return err
-	}
-
-	err = tx.Commit().Error
-	if err != nil {
-		return err
-	}
-
-	return err
-}
-
-func AddProductImage(productID uint64, create *CreateProduct) error {
-	var (
-		err    error
-		image  ProductImages
-		images []ProductImages
-	)
-
-	image = ProductImages{
-		Class:     general.ProductAvatar,
-		ProductID: productID,
-		Image:     create.Avatar,
-	}
-
-	images = append(images, image)
-
-	for _, img := range create.Images {
-		image = ProductImages{
-			Class:     general.ProductImage,
-			ProductID: productID,
-			Image:     img,
-		}
-		images = append(images, image)
-	}
-
-	for _, img := range create.DetailImages {
-		image = ProductImages{
-			Class:     general.ProductDetailImage,
-			ProductID: productID,
-			Image:     img,
-		}
-		images = append(images, image)
-	}
-
-	collection := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
-
-	for _, img := range images {
-		err = collection.Insert(img)
-		if err != nil {
-			break
-		}
-	}
-
-	return err
-}
-
-func AddProductSize(productID uint64, create *CreateProduct) error {
-	var (
-		err   error
-		size  ProductSize
-		sizes []ProductSize
-	)
-
-	for _, si := range create.Size {
-		size = ProductSize{
-			ProductID: productID,
-			Size:      si,
-		}
-		sizes = append(sizes, size)
-	}
-
-	collection := orm.MDSession.DB(orm.MD).C("productsize")
-	orm.MDSession.Refresh()
-
-	for _, si := range sizes {
-		err = collection.Insert(si)
-		if err != nil {
-			break
-		}
-	}
-
-	return err
-}
-
-func AddProductColor(productID uint64, create *CreateProduct) error {
-	var (
-		err    error
-		color  ProductColor
-		colors []ProductColor
-	)
-
-	for _, co := range create.Color {
-		color = ProductColor{
-			ProductID: productID,
-			Color:     co,
-		}
-		colors = append(colors, color)
-	}
-
-	collection := orm.MDSession.DB(orm.MD).C("productcolors")
-	orm.MDSession.Refresh()
-
-	for _, co := range colors {
-		err = collection.Insert(co)
-		if err != nil {
-			break
-		}
-	}
-
-	return err
+	return productMedia.Save(tx, product.ID, create)
 }
 
-func (ps *ProductServiceProvider) GetProductHeader() (*[]ProductList, error) {
+func (ps *ProductServiceProvider) productHeader() (*[]ProductList, error) {
 	var (
 		err     error
 		product ProductList
-		image   ProductImages
 		header  []ProductList
 	)
 
@@ -310,27 +189,20 @@ func (ps *ProductServiceProvider) GetProductHeader() (*[]ProductList, error) {
 	}
 	defer rows.Close()
 
-	collection := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
-
 	for rows.Next() {
 		db.ScanRows(rows, &product)
-		err = collection.Find(bson.M{"productid": product.ID, "class": general.ProductImage}).One(&image)
-		if err != nil {
-			return &header, err
-		}
-		product.Avatar = image.Image
 		header = append(header, product)
 	}
 
-	return &header, nil
+	err = fillProductAvatars(header, general.ProductImage)
+
+	return &header, err
 }
 
-func (ps *ProductServiceProvider) GetProductList() (*[]ProductList, error) {
+func (ps *ProductServiceProvider) productList() (*[]ProductList, error) {
 	var (
 		err     error
 		product ProductList
-		image   ProductImages
 		list    []ProductList
 	)
 
@@ -343,64 +215,74 @@ func (ps *ProductServiceProvider) GetProductList() (*[]ProductList, error) {
 	}
 	defer rows.Close()
 
-	collection := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
-
 	for rows.Next() {
 		db.ScanRows(rows, &product)
-		err = collection.Find(bson.M{"productid": product.ID, "class": general.ProductAvatar}).One(&image)
-		if err != nil {
-			return &list, err
-		}
-		product.Avatar = image.Image
 		list = append(list, product)
 	}
 
-	return &list, nil
+	err = fillProductAvatars(list, general.ProductAvatar)
+
+	return &list, err
 }
 
-func (ps *ProductServiceProvider) GetProductByCategory(cate, pageStart, pageSize uint64) (*[]ProductList, error) {
+func (ps *ProductServiceProvider) productByCategory(cate uint64, p *utility.Pagination) (*ProductPage, error) {
 	var (
 		err     error
-		product ProductList
-		image   ProductImages
+		total   uint64
+		lastKey string
 		list    []ProductList
+		page    ProductPage
 	)
 
-	db := orm.Conn
+	query := orm.Conn.Table("product").Where("category = ? AND status = ?", cate, general.ProductOnSale)
 
-	sql := fmt.Sprintf("SELECT * FROM product WHERE category = ? AND status = ? LIMIT %d, %d LOCK IN SHARE MODE", pageStart, pageSize)
+	err = query.Count(&total).Error
+	if err != nil {
+		return &page, err
+	}
 
-	rows, err := db.Raw(sql, cate, general.ProductOnSale).Rows()
-	defer rows.Close()
+	err = paginate(query, "id", p).Find(&list).Error
 	if err != nil {
-		return &list, err
+		return &page, err
 	}
 
-	collection := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
+	keep, hasMore := p.Trim(len(list))
+	list = list[:keep]
+	if keep > 0 {
+		lastKey = uintKey(list[keep-1].ID)
+	}
 
-	for rows.Next() {
-		db.ScanRows(rows, &product)
-		err = collection.Find(bson.M{"productid": product.ID, "class": general.ProductAvatar}).One(&image)
-		if err != nil {
-			return &list, err
-		}
-		product.Avatar = image.Image
-		list = append(list, product)
+	err = fillProductAvatars(list, general.ProductAvatar)
+
+	page.List = list
+	page.Page = p.Info(total, hasMore, lastKey)
+
+	return &page, err
+}
+
+func fillProductAvatars(list []ProductList, class uint8) error {
+	ids := make([]uint64, len(list))
+	for i := range list {
+		ids[i] = list[i].ID
+	}
+
+	avatars, err := LoadAvatars(ids, class)
+	if err != nil {
+		return err
+	}
+
+	for i := range list {
+		list[i].Avatar = avatars[list[i].ID]
 	}
 
-	return &list, nil
+	return nil
 }
 
-func (ps *ProductServiceProvider) GetProInfo(id uint64) (*ProductInfo, error) {
+func (ps *ProductServiceProvider) proInfo(id uint64) (*ProductInfo, error) {
 	var (
 		err     error
 		product Product
 		info    ProductInfo
-		images  []ProductImages
-		sizes   []ProductSize
-		colors  []ProductColor
 	)
 
 	db := orm.Conn
@@ -415,18 +297,16 @@ func (ps *ProductServiceProvider) GetProInfo(id uint64) (*ProductInfo, error) {
 		TotalSale: product.TotalSale,
 		Category:  product.Category,
 		Price:     product.Price,
+		Stock:     product.Stock,
 		Detail:    product.Detail,
 	}
 
-	collection1 := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
-
-	err = collection1.Find(bson.M{"productid": id}).All(&images)
+	media, err := productMedia.Media(id)
 	if err != nil {
 		return &info, err
 	}
 
-	for _, image := range images {
+	for _, image := range media.Images {
 		switch image.Class {
 		case general.ProductAvatar:
 			continue
@@ -437,29 +317,8 @@ func (ps *ProductServiceProvider) GetProInfo(id uint64) (*ProductInfo, error) {
 		}
 	}
 
-	collection2 := orm.MDSession.DB(orm.MD).C("productsize")
-	orm.MDSession.Refresh()
-
-	err = collection2.Find(bson.M{"productid": id}).All(&sizes)
-	if err != nil {
-		return &info, err
-	}
-
-	for _, size := range sizes {
-		info.Size = append(info.Size, size.Size)
-	}
-
-	collection3 := orm.MDSession.DB(orm.MD).C("productcolors")
-	orm.MDSession.Refresh()
-
-	err = collection3.Find(bson.M{"productid": id}).All(&colors)
-	if err != nil {
-		return &info, err
-	}
-
-	for _, color := range colors {
-		info.Color = append(info.Color, color.Color)
-	}
+	info.Size = media.Sizes
+	info.Color = media.Colors
 
 	return &info, nil
 }
@@ -473,7 +332,12 @@ func (ps *ProductServiceProvider) ChangeProStatus(sta *ChangeProStatus) error {
 
 	db := orm.Conn
 
-	return db.Model(&pro).Where("id = ?", sta.ID).Update(updater).Limit(1).Error
+	err := db.Model(&pro).Where("id = ?", sta.ID).Update(updater).Limit(1).Error
+	if err == nil {
+		invalidateProduct(sta.ID)
+	}
+
+	return err
 }
 
 func (ps *ProductServiceProvider) ChangeCategory(cate *ChangeCategory) error {
@@ -483,15 +347,17 @@ func (ps *ProductServiceProvider) ChangeCategory(cate *ChangeCategory) error {
 
 	db := orm.Conn
 	err := db.Model(&pro).Where("id = ?", cate.ID).Update("category", cate.Category).Limit(1).Error
+	if err == nil {
+		invalidateProduct(cate.ID)
+	}
 
 	return err
 }
 
-func (ps *ProductServiceProvider) GetMyPage() (*[]ProductList, error) {
+func (ps *ProductServiceProvider) myPage() (*[]ProductList, error) {
 	var (
 		err     error
 		product ProductList
-		image   ProductImages
 		list    []ProductList
 	)
 
@@ -504,18 +370,12 @@ func (ps *ProductServiceProvider) GetMyPage() (*[]ProductList, error) {
 	}
 	defer rows.Close()
 
-	collection := orm.MDSession.DB(orm.MD).C("productimage")
-	orm.MDSession.Refresh()
-
 	for rows.Next() {
 		db.ScanRows(rows, &product)
-		err = collection.Find(bson.M{"productid": product.ID, "class": general.ProductAvatar}).One(&image)
-		if err != nil {
-			return &list, err
-		}
-		product.Avatar = image.Image
 		list = append(list, product)
 	}
 
-	return &list, nil
+	err = fillProductAvatars(list, general.ProductAvatar)
+
+	return &list, err
 }
@@ -0,0 +1,378 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+)
+
+const (
+	ReportDay   = "day"
+	ReportWeek  = "week"
+	ReportMonth = "month"
+
+	// ReportDate is how report queries write days.
+	ReportDate = "2006-01-02"
+
+	defaultReportLimit = 10
+	maxReportLimit     = 100
+	maxReportDays      = 3 * 366
+)
+
+var ErrReportRange = errors.New("report range must be from a day to a later or the same day, at most three years")
+
+// paidOrder matches orders which were paid, whatever happened to them
+// afterwards. Orders from before the paid time was kept are told by status.
+var paidOrder = fmt.Sprintf("(o.paid IS NOT NULL OR o.status IN (%d, %d, %d, %d, %d))",
+	general.OrderSettled, general.OrderShipped, general.OrderReceived, general.OrderFinished, general.OrderReturning)
+
+// refundedOrder matches paid orders which were returned or canceled.
+var refundedOrder = fmt.Sprintf("(o.status = %d OR (o.status = %d AND o.paid IS NOT NULL))",
+	general.OrderReturning, general.OrderCanceled)
+
+// convertedCart matches cart items which were bought with an order.
+var convertedCart = fmt.Sprintf("(c.orderid > 0 AND c.paystatus = %d)", general.ProBought)
+
+// reportPeriods group a day or a time into the period a row of a sales
+// report covers, weeks start on Monday.
+var reportPeriods = map[string]func(column string) string{
+	ReportDay: func(column string) string {
+		return "DATE_FORMAT(" + column + ", '%Y-%m-%d')"
+	},
+	ReportWeek: func(column string) string {
+		return "DATE_FORMAT(DATE_SUB(DATE(" + column + "), INTERVAL WEEKDAY(" + column + ") DAY), '%Y-%m-%d')"
+	},
+	ReportMonth: func(column string) string {
+		return "DATE_FORMAT(" + column + ", '%Y-%m')"
+	},
+}
+
+type ReportServiceProvider struct {
+}
+
+var ReportService *ReportServiceProvider = &ReportServiceProvider{}
+
+// ReportDaily is the sales of one day, orders count on the day they were
+// placed. Users are counted once per day.
+type ReportDaily struct {
+	Day            time.Time `sql:"primary_key" gorm:"column:day"`
+	Orders         uint64    `gorm:"column:orders"`
+	PaidOrders     uint64    `gorm:"column:paidorders"`
+	GMV            float64   `gorm:"column:gmv"`
+	Refunds        uint64    `gorm:"column:refunds"`
+	OrderUsers     uint64    `gorm:"column:orderusers"`
+	CartUsers      uint64    `gorm:"column:cartusers"`
+	ConvertedUsers uint64    `gorm:"column:convertedusers"`
+	Updated        time.Time `gorm:"column:updated"`
+}
+
+// ReportProduct is what one product sold on one day in paid orders.
+type ReportProduct struct {
+	Day       time.Time `sql:"primary_key" gorm:"column:day"`
+	ProductID uint64    `sql:"primary_key" gorm:"column:productid"`
+	Category  uint64    `gorm:"column:category"`
+	Quantity  uint64    `gorm:"column:quantity"`
+	Amount    float64   `gorm:"column:amount"`
+}
+
+type ReportQuery struct {
+	From   string `json:"from" validate:"required"`
+	To     string `json:"to" validate:"required"`
+	Period string `json:"period"`
+	Limit  int    `json:"limit"`
+
+	from, to time.Time
+}
+
+// SalesRow is one period of a sales report. GMV only counts paid orders,
+// Conversion is the share of users adding to their cart who ordered what
+// they added.
+type SalesRow struct {
+	Period         string  `gorm:"column:period" json:"period"`
+	Orders         uint64  `gorm:"column:orders" json:"orders"`
+	PaidOrders     uint64  `gorm:"column:paidorders" json:"paidorders"`
+	GMV            float64 `gorm:"column:gmv" json:"gmv"`
+	AOV            float64 `gorm:"-" json:"aov"`
+	Refunds        uint64  `gorm:"column:refunds" json:"refunds"`
+	RefundRate     float64 `gorm:"-" json:"refundrate"`
+	OrderUsers     uint64  `gorm:"column:orderusers" json:"orderusers"`
+	CartUsers      uint64  `gorm:"column:cartusers" json:"cartusers"`
+	ConvertedUsers uint64  `gorm:"column:convertedusers" json:"convertedusers"`
+	Conversion     float64 `gorm:"-" json:"conversion"`
+}
+
+type TopProduct struct {
+	ProductID uint64  `gorm:"column:productid" json:"productid"`
+	Name      string  `gorm:"column:name" json:"name"`
+	Quantity  uint64  `gorm:"column:quantity" json:"quantity"`
+	Amount    float64 `gorm:"column:amount" json:"amount"`
+}
+
+type TopCategory struct {
+	Category uint64  `gorm:"column:category" json:"category"`
+	Name     string  `gorm:"column:name" json:"name"`
+	Quantity uint64  `gorm:"column:quantity" json:"quantity"`
+	Amount   float64 `gorm:"column:amount" json:"amount"`
+}
+
+func (ReportDaily) TableName() string {
+	return "reportdaily"
+}
+
+func (ReportProduct) TableName() string {
+	return "reportproduct"
+}
+
+// Normalize parses the range and fills in the defaults.
+func (q *ReportQuery) Normalize() error {
+	var err error
+
+	q.from, err = time.ParseInLocation(ReportDate, q.From, time.Local)
+	if err != nil {
+		return err
+	}
+
+	q.to, err = time.ParseInLocation(ReportDate, q.To, time.Local)
+	if err != nil {
+		return err
+	}
+
+	if q.to.Before(q.from) || q.to.Sub(q.from) > maxReportDays*24*time.Hour {
+		return ErrReportRange
+	}
+
+	if q.Period == "" {
+		q.Period = ReportDay
+	}
+
+	if _, ok := reportPeriods[q.Period]; !ok {
+		return errors.New("period must be day, week or month")
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultReportLimit
+	}
+
+	if q.Limit > maxReportLimit {
+		q.Limit = maxReportLimit
+	}
+
+	return nil
+}
+
+// Refresh rebuilds the reports of the days from one to the other, both
+// included. Orders change status long after they are placed, so recent
+// days are rebuilt again and again.
+func (rsp *ReportServiceProvider) Refresh(from, to time.Time) error {
+	from = startOfDay(from)
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if err := refreshDay(day); err != nil {
+			return fmt.Errorf("report of %s: %v", day.Format(ReportDate), err)
+		}
+	}
+
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func refreshDay(day time.Time) (err error) {
+	var (
+		daily    ReportDaily
+		products []ReportProduct
+	)
+
+	next := day.AddDate(0, 0, 1)
+
+	err = orm.Conn.Raw(`SELECT COUNT(*) AS orders,
+			COALESCE(SUM(`+paidOrder+`), 0) AS paidorders,
+			COALESCE(SUM(CASE WHEN `+paidOrder+` THEN o.totalprice END), 0) AS gmv,
+			COALESCE(SUM(`+refundedOrder+`), 0) AS refunds,
+			COUNT(DISTINCT o.userid) AS orderusers
+		FROM orders o WHERE o.created >= ? AND o.created < ?`, day, next).Scan(&daily).Error
+	if err != nil {
+		return err
+	}
+
+	err = orm.Conn.Raw(`SELECT COUNT(DISTINCT c.userid) AS cartusers,
+			COUNT(DISTINCT CASE WHEN `+convertedCart+` THEN c.userid END) AS convertedusers
+		FROM cart c WHERE c.created >= ? AND c.created < ?`, day, next).Scan(&daily).Error
+	if err != nil {
+		return err
+	}
+
+	// Items bought before prices were kept with the order are valued at
+	// the current price.
+	err = orm.Conn.Raw(`SELECT op.productid AS productid, COALESCE(p.category, 0) AS category,
+			SUM(op.count) AS quantity,
+			SUM(op.count * IF(op.price > 0, op.price, COALESCE(p.price, 0))) AS amount
+		FROM orderproduct op
+			JOIN orders o ON o.id = op.orderid
+			LEFT JOIN product p ON p.id = op.productid
+		WHERE o.created >= ? AND o.created < ? AND `+paidOrder+`
+		GROUP BY op.productid, p.category`, day, next).Scan(&products).Error
+	if err != nil {
+		return err
+	}
+
+	daily.Day = day
+	daily.Updated = time.Now()
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Where("day = ?", day).Delete(&ReportDaily{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Create(&daily).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Where("day = ?", day).Delete(&ReportProduct{}).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		products[i].Day = day
+
+		err = tx.Create(&products[i]).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// Sales sums the daily reports into periods. A user counts once per
+// period however many days they were active on, so users are counted from
+// the orders and carts of the whole period rather than summed from days.
+func (rsp *ReportServiceProvider) Sales(q *ReportQuery) ([]SalesRow, error) {
+	var users []SalesRow
+
+	rows := []SalesRow{}
+	period := reportPeriods[q.Period]
+	next := q.to.AddDate(0, 0, 1)
+
+	err := orm.Conn.Raw(`SELECT `+period("day")+` AS period,
+			SUM(orders) AS orders, SUM(paidorders) AS paidorders, SUM(gmv) AS gmv,
+			SUM(refunds) AS refunds
+		FROM reportdaily WHERE day >= ? AND day <= ?
+		GROUP BY period ORDER BY period`, q.from, q.to).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = orm.Conn.Raw(`SELECT period, SUM(orderusers) AS orderusers,
+			SUM(cartusers) AS cartusers, SUM(convertedusers) AS convertedusers
+		FROM (
+			SELECT `+period("o.created")+` AS period, COUNT(DISTINCT o.userid) AS orderusers,
+				0 AS cartusers, 0 AS convertedusers
+			FROM orders o WHERE o.created >= ? AND o.created < ? GROUP BY period
+			UNION ALL
+			SELECT `+period("c.created")+` AS period, 0 AS orderusers, COUNT(DISTINCT c.userid) AS cartusers,
+				COUNT(DISTINCT CASE WHEN `+convertedCart+` THEN c.userid END) AS convertedusers
+			FROM cart c WHERE c.created >= ? AND c.created < ? GROUP BY period
+		) u GROUP BY period`, q.from, next, q.from, next).Scan(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byPeriod := make(map[string]*SalesRow, len(users))
+	for i := range users {
+		byPeriod[users[i].Period] = &users[i]
+	}
+
+	for i := range rows {
+		r := &rows[i]
+
+		if u := byPeriod[r.Period]; u != nil {
+			r.OrderUsers, r.CartUsers, r.ConvertedUsers = u.OrderUsers, u.CartUsers, u.ConvertedUsers
+		}
+
+		if r.PaidOrders > 0 {
+			r.AOV = r.GMV / float64(r.PaidOrders)
+			r.RefundRate = float64(r.Refunds) / float64(r.PaidOrders)
+		}
+
+		if r.CartUsers > 0 {
+			r.Conversion = float64(r.ConvertedUsers) / float64(r.CartUsers)
+		}
+	}
+
+	return rows, nil
+}
+
+// TopProducts are the products which sold for the most in the range.
+func (rsp *ReportServiceProvider) TopProducts(q *ReportQuery) ([]TopProduct, error) {
+	top := []TopProduct{}
+
+	err := orm.Conn.Raw(`SELECT r.productid AS productid, COALESCE(p.name, '') AS name,
+			SUM(r.quantity) AS quantity, SUM(r.amount) AS amount
+		FROM reportproduct r LEFT JOIN product p ON p.id = r.productid
+		WHERE r.day >= ? AND r.day <= ?
+		GROUP BY r.productid, p.name ORDER BY amount DESC LIMIT ?`, q.from, q.to, q.Limit).Scan(&top).Error
+
+	return top, err
+}
+
+// TopCategories are the categories which sold for the most in the range,
+// products count under the category they were in when the day was built.
+func (rsp *ReportServiceProvider) TopCategories(q *ReportQuery) ([]TopCategory, error) {
+	top := []TopCategory{}
+
+	err := orm.Conn.Raw(`SELECT r.category AS category, COALESCE(c.name, '') AS name,
+			SUM(r.quantity) AS quantity, SUM(r.amount) AS amount
+		FROM reportproduct r LEFT JOIN category c ON c.id = r.category
+		WHERE r.day >= ? AND r.day <= ?
+		GROUP BY r.category, c.name ORDER BY amount DESC LIMIT ?`, q.from, q.to, q.Limit).Scan(&top).Error
+
+	return top, err
+}
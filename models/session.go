@@ -0,0 +1,228 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+	"ShopApi/server/initcache"
+)
+
+// activeCheckInterval is how long a session found in the table is trusted
+// before it is looked up again.
+const activeCheckInterval = time.Minute
+
+type SessionServiceProvider struct {
+}
+
+var SessionService *SessionServiceProvider = &SessionServiceProvider{}
+
+// UserSession is a device a user is logged in on. LastSeen is refreshed at
+// most every few minutes, see handler.MustLogin.
+type UserSession struct {
+	ID        uint64    `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	UserID    uint64    `gorm:"column:userid" json:"-"`
+	Device    string    `json:"device"`
+	IP        string    `json:"ip"`
+	UserAgent string    `gorm:"column:useragent" json:"useragent"`
+	Created   time.Time `json:"created"`
+	LastSeen  time.Time `gorm:"column:lastseen" json:"lastseen"`
+	Current   bool      `gorm:"-" json:"current"`
+}
+
+type SessionID struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+func (UserSession) TableName() string {
+	return "usersession"
+}
+
+// idleCutoff is when a session not seen since has expired.
+func idleCutoff() time.Time {
+	return time.Now().Add(-general.SessionMaxLifetime * time.Second)
+}
+
+// Start records a new login.
+func (ssp *SessionServiceProvider) Start(userID uint64, device, ip, userAgent string) (*UserSession, error) {
+	s := UserSession{
+		UserID:    userID,
+		Device:    truncate(device, 64),
+		IP:        truncate(ip, 64),
+		UserAgent: truncate(userAgent, 255),
+		Created:   time.Now(),
+		LastSeen:  time.Now(),
+	}
+
+	err := orm.Conn.Create(&s).Error
+
+	return &s, err
+}
+
+// Touch marks the session as used now.
+func (ssp *SessionServiceProvider) Touch(id uint64) error {
+	return orm.Conn.Model(&UserSession{}).Where("id = ?", id).Update("lastseen", time.Now()).Error
+}
+
+// List returns the sessions of the user which haven't expired, most recent
+// first.
+func (ssp *SessionServiceProvider) List(userID uint64) ([]UserSession, error) {
+	var sessions []UserSession
+
+	err := orm.Conn.Where("userid = ? AND lastseen > ?", userID, idleCutoff()).Order("lastseen DESC").Find(&sessions).Error
+
+	return sessions, err
+}
+
+// Revoke logs one device of the user out, found is false when it isn't the
+// user's.
+func (ssp *SessionServiceProvider) Revoke(userID, id uint64) (found bool, err error) {
+	db := orm.Conn.Where("id = ? AND userid = ?", id, userID).Delete(&UserSession{})
+	if db.Error != nil {
+		return false, db.Error
+	}
+
+	if db.RowsAffected == 0 {
+		return false, nil
+	}
+
+	// The row is gone whatever happens to the mark, Active falls back to it.
+	markRevoked(id)
+
+	return true, nil
+}
+
+// RevokeOthers logs out every device of the user but keep.
+func (ssp *SessionServiceProvider) RevokeOthers(userID, keep uint64) error {
+	return revokeSessions(userID, keep)
+}
+
+// RevokeAll logs the user out everywhere.
+func (ssp *SessionServiceProvider) RevokeAll(userID uint64) error {
+	err := UserService.RevokeSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	// IDs start at 1000, so no session is kept.
+	return revokeSessions(userID, 0)
+}
+
+// revokeSessions deletes the sessions of the user but keep with a single
+// DELETE. The rows are locked first, so the marks go to exactly the
+// sessions deleted and a login in between waits for the DELETE.
+func revokeSessions(userID, keep uint64) (err error) {
+	var ids []uint64
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Set("gorm:query_option", "FOR UPDATE").Model(&UserSession{}).
+		Where("userid = ? AND id <> ?", userID, keep).Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Where("userid = ? AND id <> ?", userID, keep).Delete(&UserSession{}).Error
+	if err != nil {
+		return err
+	}
+
+	// The rows are gone whatever happens to the marks, Active falls back to
+	// them.
+	for _, id := range ids {
+		markRevoked(id)
+	}
+
+	return nil
+}
+
+// Active reports whether the device is still logged in. The cache is only
+// a front to the usersession table: a revoke mark answers at once, a
+// session seen in the table is trusted for activeCheckInterval, anything
+// else is looked up.
+func (ssp *SessionServiceProvider) Active(id uint64) (bool, error) {
+	if initcache.Bm.IsExist(deviceRevokedKey(id)) {
+		return false, nil
+	}
+
+	if initcache.Bm.IsExist(deviceActiveKey(id)) {
+		return true, nil
+	}
+
+	var count int
+
+	err := orm.Conn.Model(&UserSession{}).Where("id = ?", id).Count(&count).Error
+	if err != nil || count == 0 {
+		return false, err
+	}
+
+	initcache.Bm.Put(deviceActiveKey(id), "1", activeCheckInterval)
+
+	return true, nil
+}
+
+// Purge deletes the sessions idle since before the given time.
+func (ssp *SessionServiceProvider) Purge(before time.Time) (int64, error) {
+	db := orm.Conn.Where("lastseen < ?", before).Delete(&UserSession{})
+
+	return db.RowsAffected, db.Error
+}
+
+func deviceRevokedKey(id uint64) string {
+	return "session:device:revoked:" + strconv.FormatUint(id, 10)
+}
+
+func deviceActiveKey(id uint64) string {
+	return "session:device:active:" + strconv.FormatUint(id, 10)
+}
+
+// markRevoked outlives the session, so the mark can expire afterwards.
+func markRevoked(id uint64) {
+	initcache.Bm.Delete(deviceActiveKey(id))
+	initcache.Bm.Put(deviceRevokedKey(id), "1", general.SessionMaxLifetime*time.Second)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+
+	return s
+}
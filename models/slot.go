@@ -0,0 +1,450 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"sync/atomic"
+	"time"
+
+	"ShopApi/general"
+	"ShopApi/orm"
+	"ShopApi/server/initcache"
+)
+
+type SlotServiceProvider struct {
+}
+
+var SlotService *SlotServiceProvider = &SlotServiceProvider{}
+
+// slotsDisabled turns pages back to the built-in lists, set by EnableSlots.
+var slotsDisabled int32
+
+// EnableSlots switches rendering of configured slots on or off.
+func EnableSlots(enabled bool) {
+	var disabled int32
+	if !enabled {
+		disabled = 1
+	}
+
+	if atomic.SwapInt32(&slotsDisabled, disabled) != disabled {
+		initcache.ProductListEntity.InvalidateAll()
+	}
+}
+
+// Slot is a merchandising position on a page, marketing fills it with
+// products or categories and schedules when it is shown.
+type Slot struct {
+	ID      uint64     `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	Name    string     `json:"name"`
+	Page    string     `json:"page"`
+	Kind    uint8      `json:"kind"`
+	Sort    int        `json:"sort"`
+	Status  uint8      `json:"status"`
+	StartAt *time.Time `gorm:"column:startat" json:"startat"`
+	EndAt   *time.Time `gorm:"column:endat" json:"endat"`
+	Created time.Time  `json:"created"`
+	Updated time.Time  `json:"updated"`
+}
+
+type SlotItem struct {
+	ID         uint64 `sql:"auto_increment;primary_key" gorm:"column:id" json:"id"`
+	SlotID     uint64 `gorm:"column:slotid" json:"slotid"`
+	ProductID  uint64 `gorm:"column:productid" json:"productid"`
+	CategoryID uint64 `gorm:"column:categoryid" json:"categoryid"`
+	Image      string `json:"image"`
+	Sort       int    `json:"sort"`
+}
+
+type CreateSlot struct {
+	Name    string     `json:"name" validate:"required"`
+	Page    string     `json:"page" validate:"required"`
+	Kind    uint8      `json:"kind"`
+	Sort    int        `json:"sort"`
+	StartAt *time.Time `json:"startat"`
+	EndAt   *time.Time `json:"endat"`
+}
+
+type ChangeSlot struct {
+	ID      uint64     `json:"id" validate:"required"`
+	Name    string     `json:"name" validate:"required"`
+	Sort    int        `json:"sort"`
+	Status  uint8      `json:"status"`
+	StartAt *time.Time `json:"startat"`
+	EndAt   *time.Time `json:"endat"`
+}
+
+type SlotID struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+type SlotItemJSON struct {
+	ProductID  uint64 `json:"productid"`
+	CategoryID uint64 `json:"categoryid"`
+	Image      string `json:"image"`
+}
+
+// SetSlotItems replaces the items of a slot, they are shown in array order.
+type SetSlotItems struct {
+	ID    uint64         `json:"id" validate:"required"`
+	Items []SlotItemJSON `json:"items" validate:"dive"`
+}
+
+type SlotGet struct {
+	Slot
+	Items []SlotItemJSON `json:"items"`
+}
+
+type CategoryTile struct {
+	ID    uint64 `json:"id"`
+	Name  string `json:"name"`
+	Image string `json:"img"`
+}
+
+func (Slot) TableName() string {
+	return "slot"
+}
+
+func (SlotItem) TableName() string {
+	return "slotitem"
+}
+
+// ValidSlotPlacement reports whether the kind can be shown on the page.
+func ValidSlotPlacement(page string, kind uint8) bool {
+	switch page {
+	case general.SlotPageHome:
+		return kind == general.SlotBanner || kind == general.SlotFeatured || kind == general.SlotCategoryTile
+	case general.SlotPageMine:
+		return kind == general.SlotFeatured
+	}
+
+	return false
+}
+
+// ValidSlotWindow reports whether the scheduling window is well formed, an
+// open end means no limit.
+func ValidSlotWindow(start, end *time.Time) bool {
+	return start == nil || end == nil || end.After(*start)
+}
+
+func (ssp *SlotServiceProvider) CreateSlot(create *CreateSlot) (*Slot, error) {
+	slot := Slot{
+		Name:    create.Name,
+		Page:    create.Page,
+		Kind:    create.Kind,
+		Sort:    create.Sort,
+		Status:  general.SlotOnUse,
+		StartAt: create.StartAt,
+		EndAt:   create.EndAt,
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+
+	err := orm.Conn.Create(&slot).Error
+	if err == nil {
+		initcache.ProductListEntity.InvalidateAll()
+	}
+
+	return &slot, err
+}
+
+func (ssp *SlotServiceProvider) FindSlot(id uint64) (*Slot, error) {
+	var slot Slot
+
+	err := orm.Conn.Where("id = ?", id).First(&slot).Error
+
+	return &slot, err
+}
+
+func (ssp *SlotServiceProvider) ChangeSlot(change *ChangeSlot) error {
+	var slot Slot
+
+	updater := map[string]interface{}{
+		"name":    change.Name,
+		"sort":    change.Sort,
+		"status":  change.Status,
+		"startat": change.StartAt,
+		"endat":   change.EndAt,
+		"updated": time.Now(),
+	}
+
+	err := orm.Conn.Model(&slot).Where("id = ?", change.ID).Update(updater).Limit(1).Error
+	if err == nil {
+		initcache.ProductListEntity.InvalidateAll()
+	}
+
+	return err
+}
+
+func (ssp *SlotServiceProvider) DeleteSlot(id uint64) (err error) {
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+
+		if err == nil {
+			initcache.ProductListEntity.InvalidateAll()
+		}
+	}()
+
+	err = tx.Where("slotid = ?", id).Delete(SlotItem{}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Where("id = ?", id).Delete(Slot{}).Error
+}
+
+func (ssp *SlotServiceProvider) SetSlotItems(set *SetSlotItems) (err error) {
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+
+		if err == nil {
+			initcache.ProductListEntity.InvalidateAll()
+		}
+	}()
+
+	err = tx.Where("slotid = ?", set.ID).Delete(SlotItem{}).Error
+	if err != nil {
+		return err
+	}
+
+	for i, item := range set.Items {
+		err = tx.Create(&SlotItem{
+			SlotID:     set.ID,
+			ProductID:  item.ProductID,
+			CategoryID: item.CategoryID,
+			Image:      item.Image,
+			Sort:       i,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ssp *SlotServiceProvider) GetSlots(page string) (*[]SlotGet, error) {
+	var (
+		err   error
+		slots []Slot
+		items []SlotItem
+		list  []SlotGet
+	)
+
+	db := orm.Conn
+	if page != "" {
+		db = db.Where("page = ?", page)
+	}
+
+	err = db.Order("page, kind, sort, id").Find(&slots).Error
+	if err != nil || len(slots) == 0 {
+		return &list, err
+	}
+
+	err = orm.Conn.Where("slotid IN (?)", slotIDs(slots)).Order("sort, id").Find(&items).Error
+	if err != nil {
+		return &list, err
+	}
+
+	for _, slot := range slots {
+		get := SlotGet{Slot: slot}
+		for _, item := range items {
+			if item.SlotID == slot.ID {
+				get.Items = append(get.Items, SlotItemJSON{
+					ProductID:  item.ProductID,
+					CategoryID: item.CategoryID,
+					Image:      item.Image,
+				})
+			}
+		}
+		list = append(list, get)
+	}
+
+	return &list, nil
+}
+
+func slotIDs(slots []Slot) []uint64 {
+	ids := make([]uint64, len(slots))
+	for i, slot := range slots {
+		ids[i] = slot.ID
+	}
+
+	return ids
+}
+
+// activeSlotItems returns the items of the slots currently shown on the
+// page, ordered by slot and then by item. configured is false when no slot
+// is scheduled, callers then fall back to the default listing.
+func activeSlotItems(page string, kind uint8) (items []SlotItem, configured bool, err error) {
+	var (
+		slots []Slot
+		all   []SlotItem
+	)
+
+	if atomic.LoadInt32(&slotsDisabled) == 1 {
+		return nil, false, nil
+	}
+
+	now := time.Now()
+
+	err = orm.Conn.Where("page = ? AND kind = ? AND status = ?", page, kind, general.SlotOnUse).
+		Where("(startat IS NULL OR startat <= ?) AND (endat IS NULL OR endat > ?)", now, now).
+		Order("sort, id").Find(&slots).Error
+	if err != nil || len(slots) == 0 {
+		return nil, false, err
+	}
+
+	err = orm.Conn.Where("slotid IN (?)", slotIDs(slots)).Order("sort, id").Find(&all).Error
+	if err != nil {
+		return nil, true, err
+	}
+
+	for _, slot := range slots {
+		for _, item := range all {
+			if item.SlotID == slot.ID {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items, true, nil
+}
+
+// slotProducts renders product slots, products taken off sale are skipped
+// and an item image replaces the product image.
+func slotProducts(page string, kind, class uint8, fallback func() (*[]ProductList, error)) (*[]ProductList, error) {
+	var (
+		products []Product
+		list     []ProductList
+	)
+
+	items, configured, err := activeSlotItems(page, kind)
+	if err != nil {
+		return &list, err
+	}
+
+	if !configured {
+		return fallback()
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+
+	if len(ids) == 0 {
+		return &list, nil
+	}
+
+	err = orm.Conn.Where("id IN (?) AND status = ?", ids, general.ProductOnSale).Find(&products).Error
+	if err != nil {
+		return &list, err
+	}
+
+	found := make(map[uint64]Product, len(products))
+	for _, product := range products {
+		found[product.ID] = product
+	}
+
+	avatars, err := LoadAvatars(ids, class)
+	if err != nil {
+		return &list, err
+	}
+
+	for _, item := range items {
+		product, ok := found[item.ProductID]
+		if !ok {
+			continue
+		}
+
+		pl := ProductList{
+			ID:       product.ID,
+			Name:     product.Name,
+			Avatar:   avatars[product.ID],
+			Category: product.Category,
+			Price:    product.Price,
+		}
+		if item.Image != "" {
+			pl.Avatar = item.Image
+		}
+
+		list = append(list, pl)
+	}
+
+	return &list, nil
+}
+
+func categoryTiles() (*[]CategoryTile, error) {
+	var (
+		categories []Category
+		tiles      []CategoryTile
+	)
+
+	items, _, err := activeSlotItems(general.SlotPageHome, general.SlotCategoryTile)
+	if err != nil || len(items) == 0 {
+		return &tiles, err
+	}
+
+	ids := make([]uint64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.CategoryID)
+	}
+
+	err = orm.Conn.Where("id IN (?) AND status = ?", ids, general.CategoryOnUse).Find(&categories).Error
+	if err != nil {
+		return &tiles, err
+	}
+
+	found := make(map[uint64]Category, len(categories))
+	for _, category := range categories {
+		found[category.ID] = category
+	}
+
+	for _, item := range items {
+		if category, ok := found[item.CategoryID]; ok {
+			tiles = append(tiles, CategoryTile{
+				ID:    category.ID,
+				Name:  category.Name,
+				Image: item.Image,
+			})
+		}
+	}
+
+	return &tiles, nil
+}
@@ -0,0 +1,438 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"ShopApi/orm"
+	"ShopApi/utility"
+)
+
+const (
+	// Step-up verification methods
+	VerifySMS    = "sms"
+	VerifyTOTP   = "totp"
+	VerifyBackup = "backup"
+
+	backupCodeCount  = 10
+	backupCodeLength = 10
+
+	// backupCodeAlphabet leaves out 0, 1, i, l and o which are easily misread.
+	backupCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+)
+
+var (
+	ErrTOTPEnabled     = errors.New("totp is already enabled")
+	ErrTOTPNotEnrolled = errors.New("totp is not enrolled")
+	ErrWrongCode       = errors.New("wrong verification code")
+	ErrNoSecondFactor  = errors.New("no way to send a verification code")
+)
+
+type TwoFactorServiceProvider struct {
+}
+
+var TwoFactorService *TwoFactorServiceProvider = &TwoFactorServiceProvider{}
+
+// UserTOTP is the authenticator app secret of a user. It only counts once
+// Enabled, after the user proved the app works. LastStep is the last time
+// step a code was accepted for, a code can't be used twice.
+type UserTOTP struct {
+	UserID   uint64    `sql:"primary_key" gorm:"column:userid"`
+	Secret   string    `json:"-"`
+	Enabled  bool      `json:"enabled"`
+	LastStep int64     `gorm:"column:laststep" json:"-"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// UserBackupCode is a one-time code for users who lost their authenticator,
+// only its sha256 is stored.
+type UserBackupCode struct {
+	ID     uint64     `sql:"auto_increment;primary_key" gorm:"column:id"`
+	UserID uint64     `gorm:"column:userid"`
+	Code   string     `gorm:"column:code"`
+	Used   *time.Time `gorm:"column:used"`
+}
+
+// Factors are the ways a user can pass step-up verification.
+type Factors struct {
+	Phone       string   `json:"-"`
+	Methods     []string `json:"methods"`
+	TOTP        bool     `json:"totp"`
+	BackupCodes int      `json:"backupcodes"`
+}
+
+type StepUp struct {
+	Method string `json:"method" validate:"required,eq=sms|eq=totp|eq=backup"`
+	Code   string `json:"code" validate:"required,alphanum,max=16"`
+}
+
+type TOTPCode struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type TOTPEnrolment struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+func (UserTOTP) TableName() string {
+	return "usertotp"
+}
+
+func (UserBackupCode) TableName() string {
+	return "userbackupcode"
+}
+
+// Factors returns the verification methods the user has set up.
+func (tfs *TwoFactorServiceProvider) Factors(userID uint64) (*Factors, error) {
+	var (
+		err     error
+		user    User
+		totp    UserTOTP
+		factors Factors
+	)
+
+	db := orm.Conn
+
+	err = db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if PhoneBound(user.Name) {
+		factors.Phone = user.Name
+		factors.Methods = append(factors.Methods, VerifySMS)
+	}
+
+	err = db.Where("userid = ? AND enabled = ?", userID, true).First(&totp).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if err == nil {
+		factors.TOTP = true
+		factors.Methods = append(factors.Methods, VerifyTOTP)
+
+		err = db.Model(&UserBackupCode{}).Where("userid = ? AND used IS NULL", userID).Count(&factors.BackupCodes).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if factors.BackupCodes > 0 {
+			factors.Methods = append(factors.Methods, VerifyBackup)
+		}
+	}
+
+	return &factors, nil
+}
+
+// SendCode texts a verification code to the phone of the user.
+func (tfs *TwoFactorServiceProvider) SendCode(userID uint64) error {
+	factors, err := tfs.Factors(userID)
+	if err != nil {
+		return err
+	}
+
+	if factors.Phone == "" {
+		return ErrNoSecondFactor
+	}
+
+	return utility.CreateCode(factors.Phone)
+}
+
+// Verify checks a code of the given method, every code works only once.
+func (tfs *TwoFactorServiceProvider) Verify(userID uint64, method, code string) error {
+	switch method {
+	case VerifySMS:
+		factors, err := tfs.Factors(userID)
+		if err != nil {
+			return err
+		}
+
+		if factors.Phone == "" || !utility.VerifyCode(factors.Phone, code) {
+			return ErrWrongCode
+		}
+
+		return nil
+	case VerifyTOTP:
+		return tfs.verifyTOTP(userID, code)
+	case VerifyBackup:
+		return tfs.verifyBackupCode(userID, code)
+	}
+
+	return ErrWrongCode
+}
+
+func (tfs *TwoFactorServiceProvider) verifyTOTP(userID uint64, code string) error {
+	var totp UserTOTP
+
+	db := orm.Conn
+
+	err := db.Where("userid = ? AND enabled = ?", userID, true).First(&totp).Error
+	if err == gorm.ErrRecordNotFound {
+		return ErrWrongCode
+	}
+	if err != nil {
+		return err
+	}
+
+	step, ok := utility.MatchTOTP(totp.Secret, code, time.Now())
+	if !ok || step <= totp.LastStep {
+		return ErrWrongCode
+	}
+
+	// Two requests racing with the same code, only one moves LastStep.
+	result := db.Model(&UserTOTP{}).Where("userid = ? AND laststep < ?", userID, step).Update("laststep", step)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrWrongCode
+	}
+
+	return nil
+}
+
+func (tfs *TwoFactorServiceProvider) verifyBackupCode(userID uint64, code string) error {
+	result := orm.Conn.Model(&UserBackupCode{}).
+		Where("userid = ? AND code = ? AND used IS NULL", userID, hashBackupCode(code)).
+		Update("used", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrWrongCode
+	}
+
+	return nil
+}
+
+// Enroll creates a new secret for the user, replacing one which was never
+// confirmed. The secret only works after Confirm.
+func (tfs *TwoFactorServiceProvider) Enroll(userID uint64, issuer string) (*TOTPEnrolment, error) {
+	var (
+		err  error
+		user User
+		totp UserTOTP
+	)
+
+	db := orm.Conn
+
+	err = db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("userid = ?", userID).First(&totp).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if totp.Enabled {
+		return nil, ErrTOTPEnabled
+	}
+
+	secret, err := utility.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	totp = UserTOTP{
+		UserID:  userID,
+		Secret:  secret,
+		Created: time.Now(),
+		Updated: time.Now(),
+	}
+
+	err = db.Save(&totp).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrolment{
+		Secret: secret,
+		URL:    utility.TOTPURL(issuer, user.Name, secret),
+	}, nil
+}
+
+// Confirm turns TOTP on once the user entered a code of the new secret and
+// returns fresh backup codes, which are shown only this once.
+func (tfs *TwoFactorServiceProvider) Confirm(userID uint64, code string) (backupCodes []string, err error) {
+	var totp UserTOTP
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Where("userid = ? AND enabled = ?", userID, false).First(&totp).Error
+	if err == gorm.ErrRecordNotFound {
+		err = ErrTOTPNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := utility.MatchTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		err = ErrWrongCode
+		return nil, err
+	}
+
+	updater := map[string]interface{}{
+		"enabled":  true,
+		"laststep": step,
+		"updated":  time.Now(),
+	}
+
+	err = tx.Model(&UserTOTP{}).Where("userid = ?", userID).Updates(updater).Error
+	if err != nil {
+		return nil, err
+	}
+
+	backupCodes, err = replaceBackupCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return backupCodes, nil
+}
+
+// RegenerateBackupCodes throws away the remaining backup codes and returns
+// new ones.
+func (tfs *TwoFactorServiceProvider) RegenerateBackupCodes(userID uint64) (backupCodes []string, err error) {
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	var count uint64
+	err = tx.Model(&UserTOTP{}).Where("userid = ? AND enabled = ?", userID, true).Count(&count).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		err = ErrTOTPNotEnrolled
+		return nil, err
+	}
+
+	backupCodes, err = replaceBackupCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return backupCodes, nil
+}
+
+// Disable removes the secret and the backup codes of the user.
+func (tfs *TwoFactorServiceProvider) Disable(userID uint64) (err error) {
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = removeTwoFactor(tx, userID)
+
+	return err
+}
+
+func removeTwoFactor(tx *gorm.DB, userID uint64) error {
+	err := tx.Where("userid = ?", userID).Delete(&UserTOTP{}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Where("userid = ?", userID).Delete(&UserBackupCode{}).Error
+}
+
+func replaceBackupCodes(tx *gorm.DB, userID uint64) ([]string, error) {
+	err := tx.Where("userid = ?", userID).Delete(&UserBackupCode{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, backupCodeCount)
+	for i := range codes {
+		codes[i], err = generateBackupCode()
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.Create(&UserBackupCode{UserID: userID, Code: hashBackupCode(codes[i])}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+func generateBackupCode() (string, error) {
+	buf := make([]byte, backupCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	for i, b := range buf {
+		buf[i] = backupCodeAlphabet[int(b)%len(backupCodeAlphabet)]
+	}
+
+	return string(buf), nil
+}
+
+// hashBackupCode ignores case, codes are often typed from paper.
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(code)))
+	return hex.EncodeToString(sum[:])
+}
@@ -31,17 +31,25 @@
  *     Modify : 2017/07/19        Ma Chao
  *     Modify : 2017/08/10        Li Zebang
  *     Modify : 2017/08/11        Yu Yi
+ *     Modify : 2026/10/19
  */
 
 package models
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/jinzhu/gorm"
+	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
 	"ShopApi/general"
 	"ShopApi/orm"
+	"ShopApi/server/initcache"
 	"ShopApi/utility"
 )
 
@@ -50,13 +58,50 @@ type UserServiceProvider struct {
 
 var UserService *UserServiceProvider = &UserServiceProvider{}
 
+var loginLockout atomic.Value
+
+func init() {
+	SetLoginLockout(LoginLockout{
+		MaxFailures: 5,
+		Duration:    time.Minute,
+		MaxDuration: 24 * time.Hour,
+	})
+}
+
+// SetLoginLockout changes the lockout policy, a zero MaxFailures disables it.
+func SetLoginLockout(lockout LoginLockout) {
+	loginLockout.Store(lockout)
+}
+
 type User struct {
-	UserID   uint64    `sql:"auto_increment;primary_key" gorm:"column:id" json:"userid"`
-	Password string    `json:"password" validate:"required,alphanum,min=6,max=30"`
-	Name     string    `json:"name"`
-	Status   uint16    `json:"status"`
-	Created  time.Time `json:"created"`
-	Updated  time.Time `json:"updated"`
+	UserID       uint64     `sql:"auto_increment;primary_key" gorm:"column:id" json:"userid"`
+	Password     string     `json:"password" validate:"required,alphanum,min=6,max=30"`
+	Name         string     `json:"name"`
+	Status       uint16     `json:"status"`
+	FailedLogins uint32     `gorm:"column:failedlogins" json:"failedlogins"`
+	LockedUntil  *time.Time `gorm:"column:lockeduntil" json:"lockeduntil"`
+	Created      time.Time  `json:"created"`
+	Updated      time.Time  `json:"updated"`
+}
+
+// LoginLockout locks an account after MaxFailures wrong passwords in a row
+// for Duration, doubling on every further MaxFailures up to MaxDuration.
+type LoginLockout struct {
+	MaxFailures uint32
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// ErrUserSuspended is returned by Login for accounts suspended by an admin.
+var ErrUserSuspended = errors.New("user is suspended")
+
+// ErrUserLocked is returned by Login while the account is locked.
+type ErrUserLocked struct {
+	Until time.Time
+}
+
+func (e *ErrUserLocked) Error() string {
+	return "user is locked until " + e.Until.Format(time.RFC3339)
 }
 
 type UserInfo struct {
@@ -97,6 +142,16 @@ type ChangePhone struct {
 	Phone string `json:"phone" validate:"required,numeric,len=11"`
 }
 
+// DeleteAccount needs the password unless the account was created by an
+// OAuth login and has none.
+type DeleteAccount struct {
+	Password *string `json:"password" validate:"omitempty,alphanum,min=6,max=64"`
+}
+
+type UserStatusChange struct {
+	UserID uint64 `json:"userid" validate:"required"`
+}
+
 type ChangePassword struct {
 	Password *string `json:"password" validate:"required,alphanum,min=6,max=64"`
 	NewPass  *string `json:"newpassword" validate:"required,alphanum,min=6,max=64"`
@@ -110,7 +165,7 @@ func (UserInfo) TableName() string {
 	return "userinfo"
 }
 
-func (us *UserServiceProvider) Register(name, pass *string) error {
+func (us *UserServiceProvider) Register(name, pass *string) (err error) {
 	hashedPass, err := utility.GenerateHash(*pass)
 	if err != nil {
 		return err
@@ -129,7 +184,7 @@ func (us *UserServiceProvider) Register(name, pass *string) error {
 	tx := db.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
@@ -147,16 +202,8 @@ func (us *UserServiceProvider) Register(name, pass *string) error {
 	}
 
 	err = tx.Create(&info).Error
-	if err != nil {
-		return err
-	}
 
-	err = tx.Commit().Error
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return err
 }
 
 func (us *UserServiceProvider) Login(name, pass *string) (bool, uint64, error) {
@@ -171,13 +218,106 @@ func (us *UserServiceProvider) Login(name, pass *string) (bool, uint64, error) {
 		return false, 0, err
 	}
 
+	// Suspension goes first, a wrong password must not turn it into a
+	// lock which lifts itself.
+	if u.Status == general.UserInactive {
+		return false, 0, ErrUserSuspended
+	}
+
+	now := time.Now()
+	if u.Status == general.UserLocked && u.LockedUntil != nil && now.Before(*u.LockedUntil) {
+		return false, 0, &ErrUserLocked{Until: *u.LockedUntil}
+	}
+
 	if !utility.CompareHash([]byte(u.Password), *pass) {
-		return false, 0, nil
+		return false, 0, us.loginFailed(u.UserID, now)
+	}
+
+	if u.FailedLogins > 0 || u.Status == general.UserLocked {
+		updater := map[string]interface{}{
+			"failedlogins": 0,
+			"lockeduntil":  nil,
+			"status":       general.UserActive,
+		}
+
+		err = db.Model(&User{}).Where("id = ?", u.UserID).Updates(updater).Error
+		if err != nil {
+			return false, 0, err
+		}
 	}
 
 	return true, u.UserID, nil
 }
 
+// loginFailed counts a wrong password and locks the account once it reaches
+// the lockout policy, returning ErrUserLocked for the attempt that locks it.
+func (us *UserServiceProvider) loginFailed(userID uint64, now time.Time) error {
+	until, err := us.countFailedLogin(userID, now)
+	if err != nil {
+		return err
+	}
+
+	if !until.IsZero() {
+		return &ErrUserLocked{Until: until}
+	}
+
+	return nil
+}
+
+// countFailedLogin increments the counter under a row lock so concurrent
+// wrong passwords can't all read the same count and skip the lock. Every
+// failure at or past MaxFailures locks again, each further MaxFailures
+// failures double the lock up to MaxDuration.
+func (us *UserServiceProvider) countFailedLogin(userID uint64, now time.Time) (until time.Time, err error) {
+	var u User
+
+	policy := loginLockout.Load().(LoginLockout)
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Set("gorm:query_option", "FOR UPDATE").Select("id, status, failedlogins").Where("id = ?", userID).First(&u).Error
+	if err != nil {
+		return until, err
+	}
+
+	failed := u.FailedLogins + 1
+	updater := map[string]interface{}{"failedlogins": failed}
+
+	if policy.MaxFailures > 0 && failed >= policy.MaxFailures {
+		lock := policy.Duration
+		for i := uint32(1); i < failed/policy.MaxFailures && lock < policy.MaxDuration; i++ {
+			lock *= 2
+		}
+		if lock > policy.MaxDuration {
+			lock = policy.MaxDuration
+		}
+
+		until = now.Add(lock)
+		updater["status"] = general.UserLocked
+		updater["lockeduntil"] = until
+	}
+
+	// Only an active or locked account is counted and locked, a suspension
+	// or deletion which raced the login stays.
+	db := tx.Model(&User{}).Where("id = ? AND status IN (?)", userID, []uint16{general.UserActive, general.UserLocked}).Updates(updater)
+	if db.Error != nil {
+		return time.Time{}, db.Error
+	}
+
+	if db.RowsAffected == 0 {
+		return time.Time{}, nil
+	}
+
+	return until, nil
+}
+
 func (us *UserServiceProvider) GetUserInfo(UserID uint64) (*UserGet, error) {
 	var (
 		err error
@@ -241,9 +381,8 @@ func (us *UserServiceProvider) ChangeUserAvatar(avatar *UserAvatar) error {
 	return err
 }
 
-func (us *UserServiceProvider) ChangePhone(userID uint64, phone string) error {
+func (us *UserServiceProvider) ChangePhone(userID uint64, phone string) (err error) {
 	var (
-		err  error
 		user User
 		info UserInfo
 	)
@@ -254,7 +393,7 @@ func (us *UserServiceProvider) ChangePhone(userID uint64, phone string) error {
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
@@ -273,16 +412,13 @@ func (us *UserServiceProvider) ChangePhone(userID uint64, phone string) error {
 	return err
 }
 
-func (us *UserServiceProvider) ChangePassword(changePassword *ChangePassword, id uint64) (bool, error) {
-	var (
-		user User
-		err  error
-	)
+func (us *UserServiceProvider) ChangePassword(changePassword *ChangePassword, id uint64) (changed bool, err error) {
+	var user User
 
 	tx := orm.Conn.Begin()
 	defer func() {
 		if err != nil {
-			err = tx.Rollback().Error
+			tx.Rollback()
 		} else {
 			err = tx.Commit().Error
 		}
@@ -298,6 +434,9 @@ func (us *UserServiceProvider) ChangePassword(changePassword *ChangePassword, id
 	}
 
 	hashPass, err := utility.GenerateHash(*changePassword.NewPass)
+	if err != nil {
+		return false, err
+	}
 
 	updater := map[string]interface{}{"password": hashPass}
 
@@ -305,3 +444,165 @@ func (us *UserServiceProvider) ChangePassword(changePassword *ChangePassword, id
 
 	return true, err
 }
+
+// SetStatus suspends or reactivates an account, reactivating also lifts a
+// lockout. Deleted accounts are left alone, found is false for them.
+func (us *UserServiceProvider) SetStatus(userID uint64, status uint16) (found bool, err error) {
+	updater := map[string]interface{}{
+		"status":  status,
+		"updated": time.Now(),
+	}
+
+	if status == general.UserActive {
+		updater["failedlogins"] = 0
+		updater["lockeduntil"] = nil
+	}
+
+	db := orm.Conn.Model(&User{}).Where("id = ? AND status <> ?", userID, general.UserDeleted).Updates(updater)
+	if db.Error != nil {
+		return false, db.Error
+	}
+
+	if db.RowsAffected == 0 {
+		var u User
+
+		err = orm.Conn.Where("id = ? AND status <> ?", userID, general.UserDeleted).First(&u).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return false, nil
+			}
+
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// DeleteAccount closes the account after checking its password. The phone
+// number, profile, addresses, cart, avatar and linked OAuth accounts are
+// wiped, orders are kept for accounting and still point at the user ID.
+func (us *UserServiceProvider) DeleteAccount(userID uint64, pass *string) (deleted bool, err error) {
+	var user User
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	err = tx.Where("id = ? AND status <> ?", userID, general.UserDeleted).First(&user).Error
+	if err != nil {
+		return false, err
+	}
+
+	if PhoneBound(user.Name) && (pass == nil || !utility.CompareHash([]byte(user.Password), *pass)) {
+		return false, nil
+	}
+
+	// The name and phone are unique, a placeholder frees the number for a
+	// new registration.
+	placeholder := fmt.Sprintf("deleted-%d", userID)
+
+	updater := map[string]interface{}{
+		"name":         placeholder,
+		"password":     "",
+		"status":       general.UserDeleted,
+		"failedlogins": 0,
+		"lockeduntil":  nil,
+		"updated":      time.Now(),
+	}
+
+	err = tx.Model(&User{}).Where("id = ?", userID).Updates(updater).Error
+	if err != nil {
+		return false, err
+	}
+
+	info := map[string]interface{}{
+		"nickname": "",
+		"phone":    placeholder,
+		"sex":      general.Sex,
+	}
+
+	err = tx.Model(&UserInfo{}).Where("userid = ?", userID).Updates(info).Error
+	if err != nil {
+		return false, err
+	}
+
+	address := map[string]interface{}{
+		"name":    "",
+		"phone":   "",
+		"area":    "",
+		"address": "",
+		"updated": time.Now(),
+	}
+
+	err = tx.Model(&Address{}).Where("userid = ?", userID).Updates(address).Error
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Where("userid = ? AND status = ?", userID, general.ProInCart).Delete(&Cart{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Where("userid = ?", userID).Delete(&UserIdentity{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	err = removeTwoFactor(tx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	collection := orm.MDSession.DB(orm.MD).C("useravatar")
+	orm.MDSession.Refresh()
+	err = collection.RemoveId(userID)
+	if err == mgo.ErrNotFound {
+		err = nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func revokedKey(userID uint64) string {
+	return "session:revoked:" + strconv.FormatUint(userID, 10)
+}
+
+// RevokeSessions logs the user out of every session started so far. The
+// mark outlives the longest session, so it can expire afterwards.
+func (us *UserServiceProvider) RevokeSessions(userID uint64) error {
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	return initcache.Bm.Put(revokedKey(userID), now, general.SessionMaxLifetime*time.Second)
+}
+
+// SessionRevoked reports whether a session of the user started at loginAt,
+// in Unix nanoseconds, was revoked.
+func (us *UserServiceProvider) SessionRevoked(userID uint64, loginAt int64) bool {
+	var s string
+
+	switch v := initcache.Bm.Get(revokedKey(userID)).(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return false
+	}
+
+	revokedAt, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return loginAt <= revokedAt
+}
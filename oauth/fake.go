@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package oauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// FakeServer is an OAuth2 server for development and tests. Its authorize
+// endpoint grants access at once to the user named by the "login" parameter,
+// "tester" by default. Pair it with a KindOAuth2 provider whose URLs are
+// the server's /authorize, /token and /userinfo.
+type FakeServer struct {
+	ClientID     string
+	ClientSecret string
+
+	lock   sync.Mutex
+	codes  map[string]string
+	tokens map[string]string
+}
+
+func NewFakeServer(clientID, clientSecret string) *FakeServer {
+	return &FakeServer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		codes:        map[string]string{},
+		tokens:       map[string]string{},
+	}
+}
+
+func (f *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/authorize"):
+		f.authorize(w, r)
+	case strings.HasSuffix(r.URL.Path, "/token"):
+		f.token(w, r)
+	case strings.HasSuffix(r.URL.Path, "/userinfo"):
+		f.userinfo(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *FakeServer) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != f.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.String() == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	login := q.Get("login")
+	if login == "" {
+		login = "tester"
+	}
+
+	code := f.issue(f.codes, login)
+
+	v := redirect.Query()
+	v.Set("code", code)
+	v.Set("state", q.Get("state"))
+	redirect.RawQuery = v.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (f *FakeServer) token(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("client_id") != f.ClientID || r.FormValue("client_secret") != f.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	login, ok := f.redeem(f.codes, r.FormValue("code"))
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": f.issue(f.tokens, login),
+		"token_type":   "Bearer",
+	})
+}
+
+func (f *FakeServer) userinfo(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	f.lock.Lock()
+	login, ok := f.tokens[token]
+	f.lock.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"sub":  "fake-" + login,
+		"name": login,
+	})
+}
+
+func (f *FakeServer) issue(m map[string]string, login string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	secret := hex.EncodeToString(b)
+
+	f.lock.Lock()
+	m[secret] = login
+	f.lock.Unlock()
+
+	return secret
+}
+
+// redeem spends a code, codes work once.
+func (f *FakeServer) redeem(m map[string]string, secret string) (string, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	login, ok := m[secret]
+	delete(m, secret)
+
+	return login, ok
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
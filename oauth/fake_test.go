@@ -0,0 +1,181 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const fakeRedirect = "https://shop.example.com/oauth"
+
+func newFakeProvider(t *testing.T, secret string) (Provider, *httptest.Server) {
+	srv := httptest.NewServer(NewFakeServer("shop", "secret"))
+	t.Cleanup(srv.Close)
+
+	p, err := New("fake", Config{
+		Kind:         KindOAuth2,
+		ClientID:     "shop",
+		ClientSecret: secret,
+		RedirectURL:  fakeRedirect,
+		AuthorizeURL: srv.URL + "/authorize",
+		TokenURL:     srv.URL + "/token",
+		ProfileURL:   srv.URL + "/userinfo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p, srv
+}
+
+// authorize follows the authorize URL like a browser would and returns the
+// query the user is sent back to the shop with.
+func authorize(t *testing.T, authorizeURL string) url.Values {
+	noRedirect := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := noRedirect.Get(authorizeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize answered %d", resp.StatusCode)
+	}
+
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := back.Scheme + "://" + back.Host + back.Path; got != fakeRedirect {
+		t.Errorf("redirected to %s, want %s", got, fakeRedirect)
+	}
+
+	return back.Query()
+}
+
+func TestFakeRoundTrip(t *testing.T) {
+	p, _ := newFakeProvider(t, "secret")
+	ctx := context.Background()
+
+	back := authorize(t, p.AuthorizeURL("state-1")+"&login=alice")
+	if back.Get("state") != "state-1" {
+		t.Errorf("state = %q, want state-1", back.Get("state"))
+	}
+
+	token, err := p.Exchange(ctx, back.Get("code"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := p.Profile(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Profile{Provider: "fake", ID: "fake-alice", Nickname: "alice"}
+	if *profile != want {
+		t.Errorf("profile = %+v, want %+v", *profile, want)
+	}
+
+	// codes work once
+	if _, err = p.Exchange(ctx, back.Get("code")); err == nil {
+		t.Error("a code was exchanged twice")
+	}
+
+	// without login the fake signs in as tester
+	back = authorize(t, p.AuthorizeURL("state-2"))
+	token, err = p.Exchange(ctx, back.Get("code"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err = p.Profile(ctx, token)
+	if err != nil || profile.ID != "fake-tester" {
+		t.Errorf("default login: %+v, %v", profile, err)
+	}
+}
+
+func TestFakeRejects(t *testing.T) {
+	ctx := context.Background()
+
+	p, srv := newFakeProvider(t, "wrong")
+
+	back := authorize(t, p.AuthorizeURL("s"))
+	if _, err := p.Exchange(ctx, back.Get("code")); err == nil {
+		t.Error("exchange with a wrong client secret succeeded")
+	}
+
+	good, _ := newFakeProvider(t, "secret")
+	if _, err := good.Exchange(ctx, "no-such-code"); err == nil {
+		t.Error("exchange of an unknown code succeeded")
+	}
+
+	if _, err := good.Profile(ctx, &Token{AccessToken: "no-such-token"}); err == nil {
+		t.Error("profile with an unknown token succeeded")
+	}
+
+	resp, err := http.Get(srv.URL + "/authorize?client_id=other&redirect_uri=" + url.QueryEscape(fakeRedirect))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("authorize for an unknown client answered %d", resp.StatusCode)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	p, _ := newFakeProvider(t, "secret")
+	Register(p)
+
+	found, err := Lookup("fake")
+	if err != nil || found != p {
+		t.Errorf("Lookup(fake) = %v, %v", found, err)
+	}
+
+	if _, err = Lookup("nobody"); err != ErrUnknownProvider {
+		t.Errorf("Lookup(nobody) error = %v, want %v", err, ErrUnknownProvider)
+	}
+
+	if _, err = New("x", Config{Kind: "saml"}); err != ErrUnknownKind {
+		t.Errorf("New with kind saml error = %v, want %v", err, ErrUnknownKind)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oauth provider")
+	ErrUnknownKind     = errors.New("unknown oauth provider kind")
+
+	client = &http.Client{Timeout: 10 * time.Second}
+
+	providersLock sync.RWMutex
+	providers     = map[string]Provider{}
+)
+
+// Provider signs users in with an OAuth2 authorization code.
+type Provider interface {
+	// Name is the provider in URLs and in the useridentity table.
+	Name() string
+
+	// AuthorizeURL is where the client sends the user to grant access, the
+	// provider echoes state back with the code.
+	AuthorizeURL(state string) string
+
+	// Exchange trades the code for a token.
+	Exchange(ctx context.Context, code string) (*Token, error)
+
+	// Profile fetches the user the token belongs to.
+	Profile(ctx context.Context, token *Token) (*Profile, error)
+}
+
+// Token is an access token. OpenID is set by providers which return the
+// user ID along with the token.
+type Token struct {
+	AccessToken string
+	OpenID      string
+}
+
+// Profile is the external account, ID is stable per provider.
+type Profile struct {
+	Provider string
+	ID       string
+	Nickname string
+	Avatar   string
+}
+
+// Config describes a provider. Kind is KindWeChat or KindOAuth2, the URLs
+// are only read for KindOAuth2.
+type Config struct {
+	Kind         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scope        string
+	AuthorizeURL string
+	TokenURL     string
+	ProfileURL   string
+}
+
+const (
+	KindWeChat = "wechat"
+	KindOAuth2 = "oauth2"
+)
+
+// New builds a provider named name.
+func New(name string, conf Config) (Provider, error) {
+	switch conf.Kind {
+	case KindWeChat:
+		return &weChat{name: name, conf: conf}, nil
+	case KindOAuth2:
+		return &oauth2{name: name, conf: conf}, nil
+	}
+
+	return nil, ErrUnknownKind
+}
+
+// Register makes a provider available to Lookup, replacing one of the same
+// name.
+func Register(p Provider) {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+
+	providers[p.Name()] = p
+}
+
+// Lookup returns the provider registered under name.
+func Lookup(name string) (Provider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	return p, nil
+}
+
+// getJSON sends req and decodes a JSON answer into v.
+func getJSON(ctx context.Context, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth: %s answered %d: %s", req.URL.Host, resp.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, v)
+}
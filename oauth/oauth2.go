@@ -0,0 +1,126 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// oauth2 is a standard authorization code provider, its profile endpoint
+// answers {"sub": "...", "name": "...", "picture": "..."}.
+type oauth2 struct {
+	name string
+	conf Config
+}
+
+func (p *oauth2) Name() string {
+	return p.name
+}
+
+func (p *oauth2) AuthorizeURL(state string) string {
+	v := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.conf.ClientID},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"state":         {state},
+	}
+
+	if p.conf.Scope != "" {
+		v.Set("scope", p.conf.Scope)
+	}
+
+	return p.conf.AuthorizeURL + "?" + v.Encode()
+}
+
+func (p *oauth2) Exchange(ctx context.Context, code string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"client_id":     {p.conf.ClientID},
+		"client_secret": {p.conf.ClientSecret},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+
+	if err = getJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.AccessToken == "" {
+		return nil, errors.New("oauth: no access token: " + resp.Error)
+	}
+
+	return &Token{AccessToken: resp.AccessToken}, nil
+}
+
+func (p *oauth2) Profile(ctx context.Context, token *Token) (*Profile, error) {
+	req, err := http.NewRequest(http.MethodGet, p.conf.ProfileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var resp struct {
+		Sub     string `json:"sub"`
+		Name    string `json:"name"`
+		Picture string `json:"picture"`
+	}
+
+	if err = getJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Sub == "" {
+		return nil, errors.New("oauth: profile without sub")
+	}
+
+	return &Profile{
+		Provider: p.name,
+		ID:       resp.Sub,
+		Nickname: resp.Name,
+		Avatar:   resp.Picture,
+	}, nil
+}
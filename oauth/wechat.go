@@ -0,0 +1,155 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	weChatAuthorizeURL = "https://open.weixin.qq.com/connect/qrconnect"
+	weChatTokenURL     = "https://api.weixin.qq.com/sns/oauth2/access_token"
+	weChatProfileURL   = "https://api.weixin.qq.com/sns/userinfo"
+	weChatScope        = "snsapi_login"
+)
+
+// weChat is WeChat website login. Users are identified by unionid when the
+// app is bound to an open platform account, by openid otherwise.
+type weChat struct {
+	name string
+	conf Config
+}
+
+type weChatError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (e weChatError) err() error {
+	if e.ErrCode == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("oauth: wechat error %d: %s", e.ErrCode, e.ErrMsg)
+}
+
+func (p *weChat) Name() string {
+	return p.name
+}
+
+func (p *weChat) AuthorizeURL(state string) string {
+	scope := p.conf.Scope
+	if scope == "" {
+		scope = weChatScope
+	}
+
+	v := url.Values{
+		"appid":         {p.conf.ClientID},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {scope},
+		"state":         {state},
+	}
+
+	return weChatAuthorizeURL + "?" + v.Encode() + "#wechat_redirect"
+}
+
+func (p *weChat) Exchange(ctx context.Context, code string) (*Token, error) {
+	v := url.Values{
+		"appid":      {p.conf.ClientID},
+		"secret":     {p.conf.ClientSecret},
+		"code":       {code},
+		"grant_type": {"authorization_code"},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, weChatTokenURL+"?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		weChatError
+		AccessToken string `json:"access_token"`
+		OpenID      string `json:"openid"`
+	}
+
+	if err = getJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if err = resp.err(); err != nil {
+		return nil, err
+	}
+
+	return &Token{AccessToken: resp.AccessToken, OpenID: resp.OpenID}, nil
+}
+
+func (p *weChat) Profile(ctx context.Context, token *Token) (*Profile, error) {
+	v := url.Values{
+		"access_token": {token.AccessToken},
+		"openid":       {token.OpenID},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, weChatProfileURL+"?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		weChatError
+		OpenID     string `json:"openid"`
+		UnionID    string `json:"unionid"`
+		Nickname   string `json:"nickname"`
+		HeadImgURL string `json:"headimgurl"`
+	}
+
+	if err = getJSON(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+
+	if err = resp.err(); err != nil {
+		return nil, err
+	}
+
+	id := resp.UnionID
+	if id == "" {
+		id = resp.OpenID
+	}
+
+	return &Profile{
+		Provider: p.name,
+		ID:       id,
+		Nickname: resp.Nickname,
+		Avatar:   resp.HeadImgURL,
+	}, nil
+}
@@ -0,0 +1,150 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo"
+
+	"ShopApi/general"
+	"ShopApi/general/errcode"
+	"ShopApi/log"
+)
+
+// maxPeekBody bounds how much of a body ByMobile reads.
+const maxPeekBody = 1 << 16
+
+// KeyFunc names the bucket a request is counted in, an empty key skips the
+// limit.
+type KeyFunc func(c echo.Context) string
+
+// ByIP counts requests per client address.
+func ByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// ByUserID counts requests per logged in user, it must run after
+// handler.MustLogin.
+func ByUserID(c echo.Context) string {
+	userID := c.Get(general.ContextUserID)
+	if userID == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("user:%v", userID)
+}
+
+// ByMobile counts requests per "mobile" of a JSON body, so one account
+// can't be attacked from many addresses. The body is left for the handler.
+func ByMobile(c echo.Context) string {
+	req := c.Request()
+	if req.Body == nil {
+		return ""
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxPeekBody+1))
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil || len(body) > maxPeekBody {
+		return ""
+	}
+
+	var v struct {
+		Mobile string `json:"mobile"`
+	}
+	if json.Unmarshal(body, &v) != nil || v.Mobile == "" {
+		return ""
+	}
+
+	return "mobile:" + v.Mobile
+}
+
+// Limit throttles requests of the route group with the rule configured by
+// SetRule, counting each request once in the bucket of every key. Groups
+// without a rule are not limited.
+func Limit(group string, keys ...KeyFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			rule, ok := ruleOf(group)
+			if !ok {
+				return next(c)
+			}
+
+			for _, keyOf := range keys {
+				key := keyOf(c)
+				if key == "" {
+					continue
+				}
+
+				allowed, retryAfter, err := store.Take(group+":"+key, rule)
+				if err != nil {
+					// Failing open keeps the shop usable when the cache is down.
+					general.RequestLog(c).Error("[ERROR] RateLimit Take:", err)
+					continue
+				}
+
+				if !allowed {
+					return TooManyRequests(c, retryAfter)
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// TooManyRequests answers 429 asking the client to retry after the given
+// time.
+func TooManyRequests(c echo.Context, retryAfter time.Duration) error {
+	seconds := int(retryAfter.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	err := errors.New("Too many requests, retry after " + strconv.Itoa(seconds) + "s")
+	log.Logger.Warn("[WARN] RateLimit %s %s: %s", c.Request().Method, c.Path(), err.Error())
+
+	return general.NewErrorWithMessage(errcode.ErrTooManyRequests, err.Error())
+}
+
+// readCloser puts the peeked bytes back in front of the request body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,178 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ShopApi/server/initcache"
+)
+
+const keyPrefix = "ratelimit:"
+
+// Rule lets Burst requests through at once and refills the bucket at
+// Burst per Period.
+type Rule struct {
+	Burst  int
+	Period time.Duration
+}
+
+func (r Rule) rate() float64 {
+	return float64(r.Burst) / r.Period.Seconds()
+}
+
+// Store keeps the token buckets. Take spends one token of the bucket under
+// key and, when the bucket is empty, tells how long until the next token.
+type Store interface {
+	Take(key string, rule Rule) (ok bool, retryAfter time.Duration, err error)
+}
+
+var (
+	store Store = &CacheStore{}
+
+	rulesLock sync.RWMutex
+	rules     = map[string]Rule{}
+)
+
+// UseStore replaces the default store, which keeps buckets in initcache.
+func UseStore(s Store) {
+	store = s
+}
+
+// SetRule configures the limit of a route group, a zero Burst removes it.
+func SetRule(group string, rule Rule) {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+
+	if rule.Burst <= 0 || rule.Period <= 0 {
+		delete(rules, group)
+		return
+	}
+
+	rules[group] = rule
+}
+
+func ruleOf(group string) (Rule, bool) {
+	rulesLock.RLock()
+	defer rulesLock.RUnlock()
+
+	rule, ok := rules[group]
+	return rule, ok
+}
+
+// CacheStore keeps buckets in initcache.Bm. Updates are serialised within
+// the process only, with a shared redis or memcache several instances may
+// let a few more requests through than the rule allows.
+type CacheStore struct {
+	lock sync.Mutex
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (cs *CacheStore) Take(key string, rule Rule) (bool, time.Duration, error) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	now := time.Now()
+	key = keyPrefix + key
+
+	b, ok := parseBucket(initcache.Bm.Get(key))
+	if !ok {
+		b = bucket{tokens: float64(rule.Burst), last: now}
+	}
+
+	allowed, retryAfter := b.take(rule, now)
+
+	// A bucket left alone for a whole period is full again, so it can
+	// expire then.
+	err := initcache.Bm.Put(key, b.String(), rule.Period)
+
+	return allowed, retryAfter, err
+}
+
+func (b *bucket) take(rule Rule, now time.Time) (bool, time.Duration) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(rule.Burst), b.tokens+elapsed*rule.rate())
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / rule.rate()
+
+	return false, time.Duration(math.Ceil(wait)) * time.Second
+}
+
+func (b bucket) String() string {
+	return fmt.Sprintf("%.4f:%d", b.tokens, b.last.UnixNano())
+}
+
+// parseBucket reads a bucket back, redis and memcache return bytes.
+func parseBucket(v interface{}) (bucket, bool) {
+	var s string
+
+	switch value := v.(type) {
+	case string:
+		s = value
+	case []byte:
+		s = string(value)
+	default:
+		return bucket{}, false
+	}
+
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return bucket{}, false
+	}
+
+	tokens, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return bucket{}, false
+	}
+
+	nano, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return bucket{}, false
+	}
+
+	return bucket{tokens: tokens, last: time.Unix(0, nano)}, true
+}
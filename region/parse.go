@@ -0,0 +1,125 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package region
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Match is what Parse recognised in a free-text area, missing levels are "".
+type Match struct {
+	Province string
+	City     string
+	District string
+}
+
+// suffixes are left out when people write a region name, longest first.
+var suffixes = []string{"维吾尔自治区", "壮族自治区", "回族自治区", "特别行政区", "自治区", "自治州", "地区", "省", "市", "区", "县", "盟", "旗"}
+
+var separators = strings.NewReplacer(" ", "", "　", "", ",", "", "，", "", "/", "", "-", "", "\t", "")
+
+// Parse makes a best effort to find the regions of an area like
+// "浙江省 杭州市 西湖区", "浙江杭州西湖区" or "北京市朝阳区".
+func Parse(area string) Match {
+	ds := dataset()
+	s := separators.Replace(area)
+
+	province, rest := ds.match("", s)
+	if province == nil {
+		return Match{}
+	}
+
+	m := Match{Province: province.Code}
+
+	city, rest := ds.match(province.Code, rest)
+	if city == nil {
+		// Municipalities are often written without the city level.
+		for _, c := range ds.children[province.Code] {
+			if district, _ := ds.match(c.Code, rest); district != nil {
+				m.City, m.District = c.Code, district.Code
+				break
+			}
+		}
+
+		return m
+	}
+
+	m.City = city.Code
+
+	if district, _ := ds.match(city.Code, rest); district != nil {
+		m.District = district.Code
+	}
+
+	return m
+}
+
+// Deepest is the code of the lowest level found.
+func (m Match) Deepest() string {
+	switch {
+	case m.District != "":
+		return m.District
+	case m.City != "":
+		return m.City
+	}
+
+	return m.Province
+}
+
+// match finds the child of parent whose full or short name starts s, the
+// longest one wins.
+func (ds *Dataset) match(parent, s string) (*Region, string) {
+	var (
+		best   *Region
+		length int
+	)
+
+	for _, r := range ds.children[parent] {
+		for _, name := range []string{r.Name, shortName(r.Name)} {
+			if len(name) > length && strings.HasPrefix(s, name) {
+				best, length = r, len(name)
+			}
+		}
+	}
+
+	return best, s[length:]
+}
+
+// shortName drops the suffix of a name, as long as two characters remain.
+func shortName(name string) string {
+	for _, suffix := range suffixes {
+		short := strings.TrimSuffix(name, suffix)
+		if short != name && utf8.RuneCountInString(short) >= 2 {
+			return short
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,269 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// Package region looks up Chinese administrative regions by their GB/T 2260
+// code: provinces end in 0000, cities in 00 and the rest are districts.
+package region
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Region levels
+const (
+	LevelProvince = 1
+	LevelCity     = 2
+	LevelDistrict = 3
+)
+
+var (
+	ErrUnknownRegion = errors.New("unknown region")
+	ErrRegionParent  = errors.New("region is not part of its parent")
+	ErrIncomplete    = errors.New("region needs a city and a district")
+)
+
+// bundled is a small dataset holding every province and the cities and
+// districts of a few of them, servers load a complete one with LoadFile.
+//
+//go:embed regions.json
+var bundled []byte
+
+var current atomic.Value
+
+func init() {
+	ds, err := Load(bundled)
+	if err != nil {
+		panic(err)
+	}
+
+	Use(ds)
+}
+
+type Region struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Level int    `json:"level"`
+	Leaf  bool   `json:"leaf"`
+
+	parent string
+}
+
+// Dataset is a tree of regions, the provinces hang under "".
+type Dataset struct {
+	regions  map[string]*Region
+	children map[string][]*Region
+}
+
+// Load reads a JSON array of {"code", "name"} objects. A district whose city
+// is absent, like the county-level cities governed by a province, hangs
+// under the province.
+func Load(data []byte) (*Dataset, error) {
+	var entries []struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	ds := &Dataset{
+		regions:  make(map[string]*Region, len(entries)),
+		children: make(map[string][]*Region),
+	}
+
+	for _, e := range entries {
+		if len(e.Code) != 6 || strings.Trim(e.Code, "0123456789") != "" || e.Name == "" {
+			return nil, fmt.Errorf("region: invalid entry %q %q", e.Code, e.Name)
+		}
+
+		if _, ok := ds.regions[e.Code]; ok {
+			return nil, fmt.Errorf("region: duplicate code %s", e.Code)
+		}
+
+		ds.regions[e.Code] = &Region{Code: e.Code, Name: e.Name, Level: level(e.Code), Leaf: true}
+	}
+
+	for _, r := range ds.regions {
+		switch r.Level {
+		case LevelCity:
+			r.parent = r.Code[:2] + "0000"
+		case LevelDistrict:
+			r.parent = r.Code[:4] + "00"
+			if _, ok := ds.regions[r.parent]; !ok {
+				r.parent = r.Code[:2] + "0000"
+			}
+		}
+
+		if r.Level != LevelProvince {
+			parent, ok := ds.regions[r.parent]
+			if !ok {
+				return nil, fmt.Errorf("region: %s %s has no parent", r.Code, r.Name)
+			}
+
+			parent.Leaf = false
+		}
+
+		ds.children[r.parent] = append(ds.children[r.parent], r)
+	}
+
+	for _, list := range ds.children {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Code < list[j].Code
+		})
+	}
+
+	return ds, nil
+}
+
+// LoadFile replaces the bundled dataset with the one in path.
+func LoadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	ds, err := Load(data)
+	if err != nil {
+		return err
+	}
+
+	Use(ds)
+
+	return nil
+}
+
+// Use makes ds the dataset of the package level functions.
+func Use(ds *Dataset) {
+	current.Store(ds)
+}
+
+func dataset() *Dataset {
+	return current.Load().(*Dataset)
+}
+
+func level(code string) int {
+	switch {
+	case code[2:] == "0000":
+		return LevelProvince
+	case code[4:] == "00":
+		return LevelCity
+	}
+
+	return LevelDistrict
+}
+
+// Lookup returns the region with code.
+func Lookup(code string) (Region, bool) {
+	r, ok := dataset().regions[code]
+	if !ok {
+		return Region{}, false
+	}
+
+	return *r, true
+}
+
+// Children returns the regions directly under code, the provinces for "".
+func Children(code string) ([]Region, bool) {
+	ds := dataset()
+
+	if code != "" {
+		if _, ok := ds.regions[code]; !ok {
+			return nil, false
+		}
+	}
+
+	list := make([]Region, len(ds.children[code]))
+	for i, r := range ds.children[code] {
+		list[i] = *r
+	}
+
+	return list, true
+}
+
+// Check reports whether province, city and district name one region from
+// the top down. The district may only be left out of cities without any.
+func Check(province, city, district string) error {
+	ds := dataset()
+
+	codes := []string{province, city, district}
+	parent := ""
+
+	for i, code := range codes {
+		if code == "" {
+			children := ds.children[parent]
+			if i == 0 || len(children) > 0 {
+				return ErrIncomplete
+			}
+
+			for _, rest := range codes[i:] {
+				if rest != "" {
+					return ErrIncomplete
+				}
+			}
+
+			return nil
+		}
+
+		r, ok := ds.regions[code]
+		if !ok {
+			return ErrUnknownRegion
+		}
+
+		if r.parent != parent {
+			return ErrRegionParent
+		}
+
+		parent = code
+	}
+
+	return nil
+}
+
+// FullName joins the names from the province down to code, a municipality
+// is named once.
+func FullName(code string) string {
+	ds := dataset()
+
+	var names []string
+	for r, ok := ds.regions[code]; ok; r, ok = ds.regions[r.parent] {
+		if len(names) == 0 || names[0] != r.Name {
+			names = append([]string{r.Name}, names...)
+		}
+	}
+
+	return strings.Join(names, "")
+}
@@ -25,51 +25,510 @@
 /*
  * Revision History:
  *     Initial: 2017/07/18        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+
+	"ShopApi/handler"
+	"ShopApi/log"
+	"ShopApi/models"
+	"ShopApi/oauth"
+	"ShopApi/ratelimit"
+	"ShopApi/server/initcache"
 )
 
+// envPrefix prefixes the environment variables overriding the config file,
+// mysql.pass is read from SHOP_MYSQL_PASS.
+const envPrefix = "SHOP"
+
 type shopServerConfig struct {
-	address   string
-	isDebug   bool
-	corsHosts []string
-	tokenKey  string
-	mysqlHost string
-	mysqlPort string
-	mysqlUser string
-	mysqlPass string
-	mysqlDb   string
-	mysqlSize int
-	MgoUrl    string
+	Server     serverConfig     `mapstructure:"server" json:"server"`
+	Middleware middlewareConfig `mapstructure:"middleware" json:"middleware"`
+	Mysql      mysqlConfig      `mapstructure:"mysql" json:"mysql"`
+	Mongodb    mongoConfig      `mapstructure:"mongodb" json:"mongodb"`
+	Product    productConfig    `mapstructure:"product" json:"product"`
+	Cache      cacheConfig      `mapstructure:"cache" json:"cache"`
+	Log        logConfig        `mapstructure:"log" json:"log"`
+	Feature    featureConfig    `mapstructure:"feature" json:"feature"`
+	RateLimit  map[string]rule  `mapstructure:"ratelimit" json:"ratelimit"`
+	Lockout    lockoutConfig    `mapstructure:"lockout" json:"lockout"`
+
+	Idempotency idempotencyConfig `mapstructure:"idempotency" json:"idempotency"`
+	OAuth       oauthConfig       `mapstructure:"oauth" json:"oauth"`
+	Verify      verifyConfig      `mapstructure:"verify" json:"verify"`
+	Region      regionConfig      `mapstructure:"region" json:"region"`
+	Address     addressConfig     `mapstructure:"address" json:"address"`
+	Report      reportConfig      `mapstructure:"report" json:"report"`
+}
+
+type serverConfig struct {
+	Address         string `mapstructure:"address" json:"address"`
+	Debug           bool   `mapstructure:"debug" json:"debug"`
+	ShutdownTimeout int    `mapstructure:"shutdowntimeout" json:"shutdowntimeout"`
+	DrainDelay      int    `mapstructure:"draindelay" json:"draindelay"`
+}
+
+type middlewareConfig struct {
+	Cors struct {
+		Hosts []string `mapstructure:"hosts" json:"hosts"`
+	} `mapstructure:"cors" json:"cors"`
+	Jwt struct {
+		TokenKey string `mapstructure:"tokenkey" json:"tokenkey"`
+	} `mapstructure:"jwt" json:"jwt"`
+}
+
+// mysqlConfig timeouts are in seconds, Port keeps its leading colon.
+type mysqlConfig struct {
+	Host         string `mapstructure:"host" json:"host"`
+	Port         string `mapstructure:"port" json:"port"`
+	User         string `mapstructure:"user" json:"user"`
+	Pass         string `mapstructure:"pass" json:"pass"`
+	DB           string `mapstructure:"db" json:"db"`
+	Size         int    `mapstructure:"size" json:"size"`
+	MaxIdle      int    `mapstructure:"maxidle" json:"maxidle"`
+	MaxLifetime  int    `mapstructure:"maxlifetime" json:"maxlifetime"`
+	Timeout      int    `mapstructure:"timeout" json:"timeout"`
+	ReadTimeout  int    `mapstructure:"readtimeout" json:"readtimeout"`
+	WriteTimeout int    `mapstructure:"writetimeout" json:"writetimeout"`
+}
+
+type mongoConfig struct {
+	URL     string `mapstructure:"url" json:"url"`
+	Timeout int    `mapstructure:"timeout" json:"timeout"`
+}
+
+type productConfig struct {
+	MediaStore  string `mapstructure:"mediastore" json:"mediastore"`
+	Placeholder string `mapstructure:"placeholder" json:"placeholder"`
+}
+
+// cacheConfig TTLs are in seconds per entity.
+type cacheConfig struct {
+	Adapter string         `mapstructure:"adapter" json:"adapter"`
+	Config  string         `mapstructure:"config" json:"config"`
+	TTL     map[string]int `mapstructure:"ttl" json:"ttl"`
+}
+
+type logConfig struct {
+	Level      string `mapstructure:"level" json:"level"`
+	Format     string `mapstructure:"format" json:"format"`
+	File       string `mapstructure:"file" json:"file"`
+	MaxSize    int    `mapstructure:"maxsize" json:"maxsize"`
+	MaxBackups int    `mapstructure:"maxbackups" json:"maxbackups"`
+}
+
+// rule lets Burst requests of a route group through per Period seconds.
+type rule struct {
+	Burst  int `mapstructure:"burst" json:"burst"`
+	Period int `mapstructure:"period" json:"period"`
+}
+
+// lockoutConfig durations are in seconds.
+type lockoutConfig struct {
+	MaxFailures int `mapstructure:"maxfailures" json:"maxfailures"`
+	Duration    int `mapstructure:"duration" json:"duration"`
+	MaxDuration int `mapstructure:"maxduration" json:"maxduration"`
+}
+
+// idempotencyConfig Window and Lease are in seconds.
+type idempotencyConfig struct {
+	Window int `mapstructure:"window" json:"window"`
+	Lease  int `mapstructure:"lease" json:"lease"`
+}
+
+// oauthConfig Fake serves a fake OAuth server at /oauth/fake and registers
+// it as the provider "fake", for development and tests only.
+type oauthConfig struct {
+	Providers map[string]oauthProviderConfig `mapstructure:"providers" json:"providers"`
+	Fake      bool                           `mapstructure:"fake" json:"fake"`
+}
+
+// oauthProviderConfig URLs are only needed by the oauth2 kind.
+type oauthProviderConfig struct {
+	Kind         string `mapstructure:"kind" json:"kind"`
+	ClientID     string `mapstructure:"clientid" json:"clientid"`
+	ClientSecret string `mapstructure:"clientsecret" json:"clientsecret"`
+	RedirectURL  string `mapstructure:"redirecturl" json:"redirecturl"`
+	Scope        string `mapstructure:"scope" json:"scope"`
+	AuthorizeURL string `mapstructure:"authorizeurl" json:"authorizeurl"`
+	TokenURL     string `mapstructure:"tokenurl" json:"tokenurl"`
+	ProfileURL   string `mapstructure:"profileurl" json:"profileurl"`
+}
+
+// verifyConfig Window is in seconds, DefaultAddress asks for step-up
+// verification before adding a default address.
+type verifyConfig struct {
+	Window         int    `mapstructure:"window" json:"window"`
+	Issuer         string `mapstructure:"issuer" json:"issuer"`
+	DefaultAddress bool   `mapstructure:"defaultaddress" json:"defaultaddress"`
+}
+
+// regionConfig File is a complete region dataset replacing the bundled one.
+type regionConfig struct {
+	File string `mapstructure:"file" json:"file"`
+}
+
+// addressConfig Limit is how many addresses a user may keep.
+type addressConfig struct {
+	Limit int `mapstructure:"limit" json:"limit"`
+}
+
+// reportConfig RefreshDays is how many days, today included, the hourly
+// job rebuilds the sales reports of.
+type reportConfig struct {
+	RefreshDays int `mapstructure:"refreshdays" json:"refreshdays"`
+}
+
+type featureConfig struct {
+	Metrics bool `mapstructure:"metrics" json:"metrics"`
+	Slots   bool `mapstructure:"slots" json:"slots"`
 }
 
 var (
 	configuration *shopServerConfig
+
+	// reloadLock serialises hot reloads, fsnotify may fire several events
+	// for one save.
+	reloadLock sync.Mutex
 )
 
-func readConfiguration() {
-	viper.AddConfigPath("./")
+func defaultConfiguration() *shopServerConfig {
+	conf := &shopServerConfig{
+		Server: serverConfig{
+			Address:         ":17071",
+			ShutdownTimeout: 15,
+			DrainDelay:      5,
+		},
+		Mysql: mysqlConfig{
+			Host:         "127.0.0.1",
+			Port:         ":3306",
+			User:         "root",
+			DB:           "shop",
+			Size:         20,
+			MaxIdle:      5,
+			MaxLifetime:  3600,
+			Timeout:      5,
+			ReadTimeout:  30,
+			WriteTimeout: 30,
+		},
+		Mongodb: mongoConfig{
+			URL:     "mongodb://127.0.0.1:27017",
+			Timeout: 1,
+		},
+		Product: productConfig{
+			MediaStore: models.MediaStoreMongo,
+		},
+		Cache: cacheConfig{
+			Adapter: initcache.AdapterMemory,
+			TTL: map[string]int{
+				"product":     600,
+				"productlist": 60,
+				"category":    1800,
+			},
+		},
+		Log: logConfig{
+			Level:      "debug",
+			Format:     log.FormatConsole,
+			MaxSize:    100,
+			MaxBackups: 7,
+		},
+		Feature: featureConfig{
+			Metrics: true,
+			Slots:   true,
+		},
+		RateLimit: map[string]rule{
+			"login":    {Burst: 10, Period: 60},
+			"register": {Burst: 5, Period: 3600},
+			"account":  {Burst: 5, Period: 60},
+			"verify":   {Burst: 5, Period: 300},
+			"sms":      {Burst: 3, Period: 600},
+		},
+		Lockout: lockoutConfig{
+			MaxFailures: 5,
+			Duration:    60,
+			MaxDuration: 86400,
+		},
+		Idempotency: idempotencyConfig{
+			Window: 86400,
+			Lease:  60,
+		},
+		OAuth: oauthConfig{
+			Providers: map[string]oauthProviderConfig{},
+		},
+		Verify: verifyConfig{
+			Window: 300,
+			Issuer: "ShopApi",
+		},
+		Address: addressConfig{
+			Limit: 20,
+		},
+		Report: reportConfig{
+			RefreshDays: 3,
+		},
+	}
+
+	conf.Middleware.Cors.Hosts = []string{}
+
+	return conf
+}
+
+// sampleConfiguration is a config.json holding every key with its default.
+func sampleConfiguration() ([]byte, error) {
+	return json.MarshalIndent(defaultConfiguration(), "", "  ")
+}
+
+// readConfiguration loads config.json from dir over the defaults, applies
+// SHOP_* environment variables and validates the result.
+func readConfiguration(dir string) error {
+	viper.AddConfigPath(dir)
 	viper.SetConfigName("config")
 
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if err := setDefaults(); err != nil {
+		return err
+	}
+
 	if err := viper.ReadInConfig(); err != nil {
-		panic(err)
-	}
-
-	configuration = &shopServerConfig{
-		address:   viper.GetString("server.address"),
-		isDebug:   viper.GetBool("server.debug"),
-		corsHosts: viper.GetStringSlice("middleware.cors.hosts"),
-		tokenKey:  viper.GetString("middleware.jwt.tokenkey"),
-		mysqlHost: viper.GetString("mysql.host"),
-		mysqlPort: viper.GetString("mysql.port"),
-		mysqlUser: viper.GetString("mysql.user"),
-		mysqlPass: viper.GetString("mysql.pass"),
-		mysqlDb:   viper.GetString("mysql.db"),
-		mysqlSize: viper.GetInt("mysql.size"),
-		MgoUrl:    viper.GetString("mongodb.url"),
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return err
+		}
+	}
+
+	conf, err := unmarshalConfiguration()
+	if err != nil {
+		return err
 	}
+
+	configuration = conf
+
+	return nil
+}
+
+// setDefaults registers every key of the default configuration, viper only
+// looks up environment variables for keys it knows.
+func setDefaults() error {
+	data, err := json.Marshal(defaultConfiguration())
+	if err != nil {
+		return err
+	}
+
+	var values map[string]interface{}
+	if err = json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			if sub, ok := v.(map[string]interface{}); ok && len(sub) > 0 {
+				walk(prefix+k+".", sub)
+				continue
+			}
+
+			viper.SetDefault(prefix+k, v)
+		}
+	}
+	walk("", values)
+
+	return nil
+}
+
+func unmarshalConfiguration() (*shopServerConfig, error) {
+	conf := &shopServerConfig{}
+
+	if err := viper.Unmarshal(conf); err != nil {
+		return nil, err
+	}
+
+	// A comma separated SHOP_MIDDLEWARE_CORS_HOSTS arrives as one string.
+	if len(conf.Middleware.Cors.Hosts) == 1 && strings.Contains(conf.Middleware.Cors.Hosts[0], ",") {
+		conf.Middleware.Cors.Hosts = strings.Split(conf.Middleware.Cors.Hosts[0], ",")
+	}
+
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// validate reports every invalid setting at once.
+func (conf *shopServerConfig) validate() error {
+	var problems []string
+
+	check := func(ok bool, format string, a ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, a...))
+		}
+	}
+
+	check(conf.Server.Address != "", "server.address is required")
+	check(conf.Server.ShutdownTimeout > 0, "server.shutdowntimeout must be positive")
+	check(conf.Server.DrainDelay >= 0, "server.draindelay can't be negative")
+
+	check(conf.Mysql.Host != "", "mysql.host is required")
+	check(strings.HasPrefix(conf.Mysql.Port, ":"), "mysql.port must look like :3306, got %q", conf.Mysql.Port)
+	check(conf.Mysql.User != "", "mysql.user is required")
+	check(conf.Mysql.DB != "", "mysql.db is required")
+	check(conf.Mysql.Size > 0, "mysql.size must be positive")
+	check(conf.Mysql.MaxIdle >= 0 && conf.Mysql.MaxIdle <= conf.Mysql.Size, "mysql.maxidle must be between 0 and mysql.size")
+	check(conf.Mysql.MaxLifetime >= 0, "mysql.maxlifetime can't be negative")
+	check(conf.Mysql.Timeout > 0 && conf.Mysql.ReadTimeout > 0 && conf.Mysql.WriteTimeout > 0, "mysql timeouts must be positive")
+
+	check(strings.HasPrefix(conf.Mongodb.URL, "mongodb://"), "mongodb.url must start with mongodb://")
+	check(conf.Mongodb.Timeout > 0, "mongodb.timeout must be positive")
+
+	check(conf.Product.MediaStore == models.MediaStoreMongo || conf.Product.MediaStore == models.MediaStoreMysql,
+		"product.mediastore must be %s or %s, got %q", models.MediaStoreMongo, models.MediaStoreMysql, conf.Product.MediaStore)
+
+	switch conf.Cache.Adapter {
+	case initcache.AdapterMemory, initcache.AdapterRedis, initcache.AdapterMemcache:
+	default:
+		check(false, "cache.adapter must be memory, redis or memcache, got %q", conf.Cache.Adapter)
+	}
+
+	for name, seconds := range conf.Cache.TTL {
+		check(seconds > 0, "cache.ttl.%s must be positive", name)
+	}
+
+	check(log.ValidLevel(conf.Log.Level), "log.level must be debug, info, warn or error, got %q", conf.Log.Level)
+	check(conf.Log.Format == log.FormatConsole || conf.Log.Format == log.FormatJSON, "log.format must be console or json, got %q", conf.Log.Format)
+	check(conf.Log.MaxSize >= 0 && conf.Log.MaxBackups >= 0, "log.maxsize and log.maxbackups can't be negative")
+
+	for group, r := range conf.RateLimit {
+		check(r.Burst >= 0 && r.Period > 0, "ratelimit.%s needs a positive period and a burst, 0 turns it off", group)
+	}
+
+	check(conf.Lockout.MaxFailures >= 0, "lockout.maxfailures can't be negative, 0 turns it off")
+	check(conf.Lockout.Duration > 0 && conf.Lockout.MaxDuration >= conf.Lockout.Duration,
+		"lockout.duration must be positive and not above lockout.maxduration")
+
+	check(conf.Idempotency.Window > 0, "idempotency.window must be positive")
+	check(conf.Idempotency.Lease > 0 && conf.Idempotency.Lease <= conf.Idempotency.Window,
+		"idempotency.lease must be positive and not above idempotency.window")
+
+	for name, p := range conf.OAuth.Providers {
+		check(p.Kind == oauth.KindWeChat || p.Kind == oauth.KindOAuth2, "oauth.providers.%s.kind must be wechat or oauth2, got %q", name, p.Kind)
+		check(p.ClientID != "" && p.ClientSecret != "" && p.RedirectURL != "", "oauth.providers.%s needs clientid, clientsecret and redirecturl", name)
+		check(p.Kind != oauth.KindOAuth2 || p.AuthorizeURL != "" && p.TokenURL != "" && p.ProfileURL != "",
+			"oauth.providers.%s needs authorizeurl, tokenurl and profileurl", name)
+	}
+
+	check(conf.Verify.Window > 0, "verify.window must be positive")
+	check(conf.Verify.Issuer != "", "verify.issuer is required")
+
+	check(conf.Address.Limit > 0, "address.limit must be positive")
+	check(conf.Report.RefreshDays > 0, "report.refreshdays must be positive")
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration:\n  " + strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+func (conf *shopServerConfig) logConfig() log.Config {
+	return log.Config{
+		Level:      conf.Log.Level,
+		Format:     conf.Log.Format,
+		File:       conf.Log.File,
+		MaxSize:    conf.Log.MaxSize,
+		MaxBackups: conf.Log.MaxBackups,
+	}
+}
+
+func (conf *shopServerConfig) shutdownTimeout() time.Duration {
+	return time.Duration(conf.Server.ShutdownTimeout) * time.Second
+}
+
+func (conf *shopServerConfig) drainDelay() time.Duration {
+	return time.Duration(conf.Server.DrainDelay) * time.Second
+}
+
+// watchConfiguration reloads config.json when it changes. Only settings
+// which are safe to change on a running server are applied: log level,
+// cache TTLs, the avatar placeholder, the slots feature, the address limit,
+// rate limits, the login lockout and step-up verification. The rest needs a
+// restart.
+func watchConfiguration() {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		reloadLock.Lock()
+		defer reloadLock.Unlock()
+
+		conf, err := unmarshalConfiguration()
+		if err != nil {
+			log.Logger.Error("[ERROR] Reload configuration, keeping the old one:", err)
+			return
+		}
+
+		applySafeSettings(conf)
+		log.Logger.Info("Configuration reloaded from %s", e.Name)
+	})
+
+	viper.WatchConfig()
+}
+
+func applySafeSettings(conf *shopServerConfig) {
+	if err := log.SetLevel(conf.Log.Level); err != nil {
+		log.Logger.Error("[ERROR] Reload log level:", err)
+	}
+
+	for name, seconds := range conf.Cache.TTL {
+		initcache.SetTTL(name, time.Duration(seconds)*time.Second)
+	}
+
+	models.SetAvatarPlaceholder(conf.Product.Placeholder)
+	models.EnableSlots(conf.Feature.Slots)
+	models.SetAddressLimit(conf.Address.Limit)
+
+	applySecurity(conf)
+}
+
+// applySecurity sets the rate limits, the login lockout and the step-up
+// verification policy.
+func applySecurity(conf *shopServerConfig) {
+	for group, r := range conf.RateLimit {
+		ratelimit.SetRule(group, ratelimit.Rule{
+			Burst:  r.Burst,
+			Period: time.Duration(r.Period) * time.Second,
+		})
+	}
+
+	models.SetLoginLockout(models.LoginLockout{
+		MaxFailures: uint32(conf.Lockout.MaxFailures),
+		Duration:    time.Duration(conf.Lockout.Duration) * time.Second,
+		MaxDuration: time.Duration(conf.Lockout.MaxDuration) * time.Second,
+	})
+
+	handler.SetStepUpPolicy(handler.StepUpPolicy{
+		Window:         time.Duration(conf.Verify.Window) * time.Second,
+		Issuer:         conf.Verify.Issuer,
+		DefaultAddress: conf.Verify.DefaultAddress,
+	})
+}
+
+func printSampleConfiguration() {
+	data, err := sampleConfiguration()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println(string(data))
 }
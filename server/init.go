@@ -30,7 +30,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -39,10 +42,17 @@ import (
 	"gopkg.in/mgo.v2"
 
 	"ShopApi/log"
+	"ShopApi/metrics"
+	"ShopApi/models"
+	"ShopApi/oauth"
 	"ShopApi/orm"
+	"ShopApi/region"
+	"ShopApi/server/initcache"
 	"ShopApi/server/router"
 
 	"ShopApi/general"
+	"ShopApi/handler"
+	"ShopApi/utility"
 )
 
 var (
@@ -51,42 +61,89 @@ var (
 
 func startServer() {
 	server = echo.New()
-	server.Use(middleware.CORS())
+	server.Use(middleware.RequestID())
+	server.Use(general.RequestLogger())
+	if configuration.Feature.Metrics {
+		server.Use(metrics.Middleware())
+		server.GET("/metrics", metrics.Handler)
+	}
+	server.Use(cors())
 	server.Use(middleware.Recover())
-	server.Use(middleware.Logger())
 
+	server.Debug = configuration.Server.Debug
 	server.HTTPErrorHandler = general.EchoRestfulErrorHandler
 	server.Validator = general.NewEchoValidator()
 
 	router.InitRouter(server)
-	log.Logger.Info("Router already init %v")
-	log.Logger.Fatal(server.Start(configuration.address))
+	initOAuth(server)
+	log.Logger.Info("Router already init")
+
+	go func() {
+		err := server.Start(configuration.Server.Address)
+		if err != nil && err != http.ErrServerClosed {
+			log.Logger.Fatal(err)
+		}
+	}()
+}
+
+// shutdown fails readiness and gives load balancers the drain delay to
+// notice, then stops accepting requests, waits for the ones in flight and
+// the background workers, and closes the databases.
+func shutdown() {
+	handler.Drain()
+	time.Sleep(configuration.drainDelay())
+
+	ctx, cancel := context.WithTimeout(context.Background(), configuration.shutdownTimeout())
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Logger.Error("[ERROR] Shutdown server:", err)
+	}
+
+	if err := utility.StopBackground(ctx); err != nil {
+		log.Logger.Error("[ERROR] Shutdown background workers:", err)
+	}
+
+	if err := orm.Conn.Close(); err != nil {
+		log.Logger.Error("[ERROR] Shutdown MySQL:", err)
+	}
+
+	orm.MDSession.Close()
+
+	log.Logger.Info("Server stopped")
+	log.Logger.Sync()
 }
 
-func init() {
-	readConfiguration()
-	initMysql()
-	InitMetal()
-	startServer()
+// cors allows the configured origins, any origin when none is configured.
+func cors() echo.MiddlewareFunc {
+	if len(configuration.Middleware.Cors.Hosts) == 0 {
+		return middleware.CORS()
+	}
+
+	return middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: configuration.Middleware.Cors.Hosts,
+	})
 }
 
 func initMysql() {
-	user := configuration.mysqlUser
-	pass := configuration.mysqlPass
-	url := configuration.mysqlHost
-	port := configuration.mysqlPort
-	sqlName := configuration.mysqlDb
+	my := configuration.Mysql
 
-	conf := fmt.Sprintf(user + ":" + pass + "@" + "tcp(" + url + port + ")/" + sqlName + "?charset=utf8&parseTime=True&loc=Local")
+	conf := fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8&parseTime=True&loc=Local&timeout=%ds&readTimeout=%ds&writeTimeout=%ds",
+		my.User, my.Pass, my.Host, my.Port, my.DB, my.Timeout, my.ReadTimeout, my.WriteTimeout)
 
 	orm.InitOrm(conf)
+
+	db := orm.Conn.DB()
+	db.SetMaxOpenConns(my.Size)
+	db.SetMaxIdleConns(my.MaxIdle)
+	db.SetConnMaxLifetime(time.Duration(my.MaxLifetime) * time.Second)
 }
 
 func InitMetal() {
 	var err error
-	url := configuration.MgoUrl
+	url := configuration.Mongodb.URL
 
-	orm.MDSession, err = mgo.DialWithTimeout(url, time.Second)
+	orm.MDSession, err = mgo.DialWithTimeout(url, time.Duration(configuration.Mongodb.Timeout)*time.Second)
 
 	if err != nil {
 		panic(err)
@@ -96,3 +153,184 @@ func InitMetal() {
 
 	orm.MDSession.SetMode(mgo.Monotonic, true)
 }
+
+func initProductMedia() {
+	err := models.UseProductMediaStore(configuration.Product.MediaStore)
+	if err != nil {
+		panic(err)
+	}
+
+	models.SetAvatarPlaceholder(configuration.Product.Placeholder)
+	models.EnableSlots(configuration.Feature.Slots)
+
+	log.Logger.Info("product media stored in %s", configuration.Product.MediaStore)
+}
+
+// initAddresses sets the address limit and loads the region dataset
+// configured in region.file, the bundled one is used without it.
+func initAddresses() {
+	models.SetAddressLimit(configuration.Address.Limit)
+
+	if configuration.Region.File == "" {
+		return
+	}
+
+	if err := region.LoadFile(configuration.Region.File); err != nil {
+		panic(err)
+	}
+
+	log.Logger.Info("regions loaded from %s", configuration.Region.File)
+}
+
+// fakeOAuthClient is the client of the fake OAuth server, it only exists
+// on servers started with oauth.fake.
+const fakeOAuthClient = "shopapi"
+
+// initOAuth registers the configured OAuth providers.
+func initOAuth(server *echo.Echo) {
+	for name, p := range configuration.OAuth.Providers {
+		provider, err := oauth.New(name, oauth.Config{
+			Kind:         p.Kind,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scope:        p.Scope,
+			AuthorizeURL: p.AuthorizeURL,
+			TokenURL:     p.TokenURL,
+			ProfileURL:   p.ProfileURL,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		oauth.Register(provider)
+	}
+
+	if !configuration.OAuth.Fake {
+		return
+	}
+
+	base := "http://127.0.0.1" + configuration.Server.Address
+	if !strings.HasPrefix(configuration.Server.Address, ":") {
+		base = "http://" + configuration.Server.Address
+	}
+
+	server.Any("/oauth/fake/*", echo.WrapHandler(oauth.NewFakeServer(fakeOAuthClient, fakeOAuthClient)))
+
+	provider, _ := oauth.New("fake", oauth.Config{
+		Kind:         oauth.KindOAuth2,
+		ClientID:     fakeOAuthClient,
+		ClientSecret: fakeOAuthClient,
+		RedirectURL:  base + "/api/v1/oauth/fake/callback",
+		AuthorizeURL: base + "/oauth/fake/authorize",
+		TokenURL:     base + "/oauth/fake/token",
+		ProfileURL:   base + "/oauth/fake/userinfo",
+	})
+	oauth.Register(provider)
+
+	log.Logger.Warn("fake OAuth provider enabled, don't use oauth.fake in production")
+}
+
+// initSMS lets servers running with server.debug write SMS codes to the
+// log. Production servers plug their gateway in with utility.SetSMSSender.
+func initSMS() {
+	if !configuration.Server.Debug {
+		return
+	}
+
+	utility.SetSMSSender(utility.DevSMSSender{})
+
+	log.Logger.Warn("SMS codes are written to the log, don't use server.debug in production")
+}
+
+// initIdempotency sets the replay window and the lease of Idempotency-Key
+// and drops expired keys every hour.
+func initIdempotency() {
+	window := time.Duration(configuration.Idempotency.Window) * time.Second
+	handler.SetIdempotencyWindow(window)
+	handler.SetIdempotencyLease(time.Duration(configuration.Idempotency.Lease) * time.Second)
+
+	hourly(func() {
+		n, err := models.IdempotencyService.Purge(time.Now().Add(-handler.IdempotencyWindow()))
+		if err != nil {
+			log.Logger.Error("[ERROR] Purge idempotency keys:", err)
+			return
+		}
+
+		log.Logger.Debug("purged %d idempotency keys", n)
+	})
+}
+
+// initSessions drops device records which have been idle for longer than a
+// session lives, every hour.
+func initSessions() {
+	hourly(func() {
+		idle := time.Duration(general.SessionMaxLifetime)*time.Second + time.Hour
+
+		n, err := models.SessionService.Purge(time.Now().Add(-idle))
+		if err != nil {
+			log.Logger.Error("[ERROR] Purge sessions:", err)
+			return
+		}
+
+		log.Logger.Debug("purged %d sessions", n)
+	})
+}
+
+// initReports rebuilds the sales reports of the last days once at start and
+// then every hour. Older days are built with tools/buildreports.
+func initReports() {
+	days := configuration.Report.RefreshDays
+
+	refresh := func() {
+		now := time.Now()
+
+		err := models.ReportService.Refresh(now.AddDate(0, 0, 1-days), now)
+		if err != nil {
+			log.Logger.Error("[ERROR] Refresh reports:", err)
+			return
+		}
+
+		log.Logger.Debug("refreshed reports of %d days", days)
+	}
+
+	utility.GoBackground(func(ctx context.Context) {
+		refresh()
+	})
+	hourly(refresh)
+}
+
+// hourly runs job every hour until the server shuts down.
+func hourly(job func()) {
+	utility.GoBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				job()
+			}
+		}
+	})
+}
+
+func initCache() {
+	err := initcache.InitCache(configuration.Cache.Adapter, configuration.Cache.Config)
+	if err != nil {
+		panic(err)
+	}
+
+	for name, seconds := range configuration.Cache.TTL {
+		initcache.SetTTL(name, time.Duration(seconds)*time.Second)
+	}
+}
+
+func initLog() {
+	err := log.Init(configuration.logConfig())
+	if err != nil {
+		panic(err)
+	}
+}
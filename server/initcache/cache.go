@@ -25,6 +25,7 @@
 /*
  * Revision History:
  *     Initial: 2017/07/19        Sun Anxiang
+ *     Modify : 2026/10/19
  */
 
 package initcache
@@ -38,9 +39,39 @@ var (
 	err error
 )
 
+const (
+	AdapterMemory   = "memory"
+	AdapterRedis    = "redis"
+	AdapterMemcache = "memcache"
+
+	defaultConfig = `{"interval": 300}`
+)
+
 func init() {
-	Bm, err = cache.NewCache("memory", `{"interval": 300}`)
+	Bm, err = cache.NewCache(AdapterMemory, defaultConfig)
 	if err != nil {
 		panic(err)
 	}
 }
+
+// InitCache replaces the default memory cache with the given beego adapter.
+// The redis and memcache adapters are only compiled in with the "redis" and
+// "memcache" build tags.
+func InitCache(adapter, config string) error {
+	if adapter == "" {
+		adapter = AdapterMemory
+	}
+
+	if config == "" && adapter == AdapterMemory {
+		config = defaultConfig
+	}
+
+	c, err := cache.NewCache(adapter, config)
+	if err != nil {
+		return err
+	}
+
+	Bm = c
+
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package initcache
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ShopApi/metrics"
+)
+
+// Entity is a kind of cached data with its own TTL. Values are stored as
+// JSON so every beego adapter can keep them.
+type Entity struct {
+	Name string
+	TTL  time.Duration
+}
+
+var (
+	ProductEntity     = &Entity{Name: "product", TTL: 10 * time.Minute}
+	ProductListEntity = &Entity{Name: "productlist", TTL: time.Minute}
+	CategoryEntity    = &Entity{Name: "category", TTL: 30 * time.Minute}
+
+	flight flightGroup
+
+	// ttlLock guards the TTLs, they change when the configuration reloads.
+	ttlLock sync.RWMutex
+)
+
+// generationTTL must stay longer than any entity TTL.
+const generationTTL = 24 * time.Hour
+
+// SetTTL changes the TTL of the named entity, unknown names are ignored.
+func SetTTL(name string, ttl time.Duration) {
+	for _, e := range []*Entity{ProductEntity, ProductListEntity, CategoryEntity} {
+		if e.Name == name && ttl > 0 && ttl < generationTTL {
+			ttlLock.Lock()
+			e.TTL = ttl
+			ttlLock.Unlock()
+		}
+	}
+}
+
+// Fetch reads the value of key into dst. On a miss load is called, only once
+// for concurrent callers of the same key, and its result is cached for the
+// TTL of the entity.
+func (e *Entity) Fetch(dst interface{}, load func() (interface{}, error), key ...interface{}) error {
+	k := e.key(key)
+
+	if data, ok := bytesOf(Bm.Get(k)); ok {
+		if json.Unmarshal(data, dst) == nil {
+			metrics.CacheRequests.WithLabelValues(e.Name, "hit").Inc()
+			return nil
+		}
+	}
+
+	metrics.CacheRequests.WithLabelValues(e.Name, "miss").Inc()
+
+	data, err, _ := flight.Do(k, func() ([]byte, error) {
+		v, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		ttlLock.RLock()
+		ttl := e.TTL
+		ttlLock.RUnlock()
+
+		Bm.Put(k, data, ttl)
+
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dst)
+}
+
+// Invalidate drops one cached value.
+func (e *Entity) Invalidate(key ...interface{}) {
+	Bm.Delete(e.key(key))
+}
+
+// InvalidateAll drops every cached value of the entity by moving it to a
+// new generation, old keys expire with their TTL.
+func (e *Entity) InvalidateAll() {
+	Bm.Put(e.generationKey(), strconv.FormatInt(time.Now().UnixNano(), 10), generationTTL)
+}
+
+func (e *Entity) generationKey() string {
+	return "gen:" + e.Name
+}
+
+func (e *Entity) generation() string {
+	if data, ok := bytesOf(Bm.Get(e.generationKey())); ok {
+		return string(data)
+	}
+
+	return "0"
+}
+
+func (e *Entity) key(parts []interface{}) string {
+	s := make([]string, len(parts))
+	for i, p := range parts {
+		s[i] = fmt.Sprint(p)
+	}
+
+	return e.Name + ":" + e.generation() + ":" + strings.Join(s, ":")
+}
+
+// bytesOf accepts what the adapters return: the memory adapter gives back
+// the stored value, redis and memcache give []byte. Anything else, such as
+// an error from a broken connection, counts as a miss.
+func bytesOf(v interface{}) ([]byte, bool) {
+	switch b := v.(type) {
+	case []byte:
+		return b, true
+	case string:
+		return []byte(b), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,146 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package initcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupShares(t *testing.T) {
+	var (
+		g     flightGroup
+		loads int32
+		wg    sync.WaitGroup
+	)
+
+	release := make(chan struct{})
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			val, err, _ := g.Do("product:1", func() ([]byte, error) {
+				atomic.AddInt32(&loads, 1)
+				<-release
+
+				return []byte("v"), nil
+			})
+			if err != nil || string(val) != "v" {
+				t.Errorf("Do = %q, %v", val, err)
+			}
+		}()
+	}
+
+	waitForDups(t, &g, "product:1", 4)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("loads = %d, want 1", loads)
+	}
+}
+
+func TestFlightGroupPanic(t *testing.T) {
+	var g flightGroup
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	waited := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic of the load was swallowed")
+			}
+		}()
+
+		g.Do("product:1", func() ([]byte, error) {
+			close(started)
+			<-release
+
+			panic("load failed")
+		})
+	}()
+
+	<-started
+
+	go func() {
+		_, err, shared := g.Do("product:1", func() ([]byte, error) {
+			return []byte("second"), nil
+		})
+		if !shared {
+			err = nil
+		}
+		waited <- err
+	}()
+
+	waitForDups(t, &g, "product:1", 1)
+	close(release)
+
+	select {
+	case err := <-waited:
+		if err != errLoadPanicked {
+			t.Errorf("waiter got %v, want %v", err, errLoadPanicked)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter still blocked after the load panicked")
+	}
+
+	val, err, _ := g.Do("product:1", func() ([]byte, error) {
+		return []byte("again"), nil
+	})
+	if err != nil || string(val) != "again" {
+		t.Errorf("Do after panic = %q, %v", val, err)
+	}
+}
+
+// waitForDups blocks until n other callers joined the load of key.
+func waitForDups(t *testing.T, g *flightGroup, key string, n int) {
+	deadline := time.Now().Add(time.Second)
+
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		c, ok := g.m[key]
+		joined := ok && c.dups >= n
+		g.mu.Unlock()
+
+		if joined {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("%d callers did not join the load", n)
+}
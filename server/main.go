@@ -25,10 +25,60 @@
 /*
  * Revision History:
  *     Initial: 2017/07/18        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package main
 
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"ShopApi/log"
+)
+
+var (
+	configDir    = flag.String("config", "./", "directory of config.json")
+	sampleConfig = flag.Bool("sample-config", false, "print a config.json with every setting and exit")
+)
+
 func main() {
+	flag.Parse()
+
+	if *sampleConfig {
+		printSampleConfiguration()
+		return
+	}
+
+	if err := readConfiguration(*configDir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	initLog()
+	initCache()
+	applySecurity(configuration)
+	initMysql()
+	InitMetal()
+	initMetrics()
+	initProductMedia()
+	initAddresses()
+	initIdempotency()
+	initSMS()
+	initSessions()
+	initReports()
+	watchConfiguration()
+
 	startServer()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	sig := <-quit
+	log.Logger.Info("Received %s, shutting down", sig)
+
+	shutdown()
 }
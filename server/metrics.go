@@ -0,0 +1,74 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package main
+
+import (
+	"gopkg.in/mgo.v2"
+
+	"ShopApi/metrics"
+	"ShopApi/orm"
+)
+
+// initMetrics exposes the MySQL pool and Mongo session stats, read each
+// time /metrics is scraped.
+func initMetrics() {
+	db := orm.Conn.DB()
+
+	metrics.NewGaugeFunc("shop_mysql_open_connections", "Open MySQL connections.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	metrics.NewGaugeFunc("shop_mysql_in_use_connections", "MySQL connections in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	metrics.NewGaugeFunc("shop_mysql_idle_connections", "Idle MySQL connections.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	metrics.NewCounterFunc("shop_mysql_wait_total", "Times a MySQL connection had to be waited for.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	metrics.NewCounterFunc("shop_mysql_wait_seconds_total", "Time spent waiting for MySQL connections.", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+
+	mgo.SetStats(true)
+
+	metrics.NewGaugeFunc("shop_mongo_sockets_alive", "Live Mongo sockets.", func() float64 {
+		return float64(mgo.GetStats().SocketsAlive)
+	})
+	metrics.NewGaugeFunc("shop_mongo_sockets_in_use", "Mongo sockets in use.", func() float64 {
+		return float64(mgo.GetStats().SocketsInUse)
+	})
+	metrics.NewCounterFunc("shop_mongo_sent_ops_total", "Operations sent to Mongo.", func() float64 {
+		return float64(mgo.GetStats().SentOps)
+	})
+	metrics.NewCounterFunc("shop_mongo_received_ops_total", "Replies received from Mongo.", func() float64 {
+		return float64(mgo.GetStats().ReceivedOps)
+	})
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/labstack/echo"
 
 	"ShopApi/handler"
+	"ShopApi/ratelimit"
 )
 
 func InitRouter(server *echo.Echo) {
@@ -43,16 +44,37 @@ func InitRouter(server *echo.Echo) {
 	}
 
 	// user
-	server.POST("/api/v1/user/register", handler.Register)
-	server.POST("/api/v1/user/login", handler.Login)
+	server.POST("/api/v1/user/register", handler.Register, ratelimit.Limit("register", ratelimit.ByIP))
+	server.POST("/api/v1/user/login", handler.Login, ratelimit.Limit("login", ratelimit.ByIP, ratelimit.ByMobile))
 	server.GET("/api/v1/user/logout", handler.Logout, handler.MustLogin)
 	server.GET("/api/v1/user/getinfo", handler.GetUserInfo, handler.MustLogin)
 	server.POST("/api/v1/user/changeavatar", handler.ChangeUserAvatar, handler.MustLogin)
 	server.POST("/api/v1/user/changeinfo", handler.ChangeUserInfo, handler.MustLogin)
-	server.POST("/api/v1/user/changephone", handler.ChangePhone, handler.MustLogin)
-	server.POST("/api/v1/user/changepass", handler.ChangePassword, handler.MustLogin)
+	server.POST("/api/v1/user/changephone", handler.ChangePhone, handler.MustLogin, ratelimit.Limit("account", ratelimit.ByUserID), handler.MustVerify)
+	server.POST("/api/v1/user/changepass", handler.ChangePassword, handler.MustLogin, ratelimit.Limit("account", ratelimit.ByUserID), handler.MustVerify)
+	server.POST("/api/v1/user/delete", handler.DeleteAccount, handler.MustLogin, ratelimit.Limit("account", ratelimit.ByUserID), handler.MustVerify)
+	server.POST("/api/v1/user/bindphone/sendcode", handler.SendBindPhoneCode, handler.MustLogin, ratelimit.Limit("sms", ratelimit.ByUserID))
+	server.POST("/api/v1/user/bindphone", handler.BindPhone, handler.MustLogin, ratelimit.Limit("account", ratelimit.ByUserID))
+	server.POST("/api/v1/user/unbindphone", handler.UnbindPhone, handler.MustLogin, handler.MustVerify)
+	server.GET("/api/v1/user/verify", handler.GetVerifyStatus, handler.MustLogin)
+	server.POST("/api/v1/user/verify", handler.Verify, handler.MustLogin, ratelimit.Limit("verify", ratelimit.ByUserID))
+	server.POST("/api/v1/user/verify/sendcode", handler.SendVerifyCode, handler.MustLogin, ratelimit.Limit("sms", ratelimit.ByUserID))
+	server.POST("/api/v1/user/totp/enroll", handler.EnrollTOTP, handler.MustLogin, handler.MustVerify)
+	server.POST("/api/v1/user/totp/confirm", handler.ConfirmTOTP, handler.MustLogin, ratelimit.Limit("verify", ratelimit.ByUserID))
+	server.POST("/api/v1/user/totp/disable", handler.DisableTOTP, handler.MustLogin, handler.MustVerify)
+	server.POST("/api/v1/user/totp/backupcodes", handler.RegenerateBackupCodes, handler.MustLogin, handler.MustVerify)
+	server.GET("/api/v1/user/sessions", handler.ListSessions, handler.MustLogin)
+	server.POST("/api/v1/user/sessions/logout", handler.LogoutSession, handler.MustLogin)
+	server.POST("/api/v1/user/sessions/logoutothers", handler.LogoutOtherSessions, handler.MustLogin)
+
+	// oauth
+	server.GET("/api/v1/oauth/:provider/authorize", handler.OAuthAuthorize)
+	server.POST("/api/v1/oauth/:provider/login", handler.OAuthLogin, ratelimit.Limit("login", ratelimit.ByIP))
+	server.GET("/api/v1/oauth/:provider/callback", handler.OAuthLogin, ratelimit.Limit("login", ratelimit.ByIP))
 
 	// address
+	server.GET("/api/v1/region/children", handler.GetRegionChildren)
+
 	server.POST("/api/v1/address/add", handler.AddAddress, handler.MustLogin)
 	server.POST("/api/v1/address/change", handler.ChangeAddress, handler.MustLogin)
 	server.GET("/api/v1/address/get", handler.GetAddress, handler.MustLogin)
@@ -69,18 +91,47 @@ func InitRouter(server *echo.Echo) {
 	server.GET("/api/v1/product/getmypage", handler.GetMyPage)
 
 	// orders
-	server.POST("/api/v1/orders/create", handler.CreateOrder, handler.MustLogin)
+	server.POST("/api/v1/orders/create", handler.CreateOrder, handler.MustLogin, handler.Idempotent)
 	server.POST("/api/v1/orders/getone", handler.GetOneOrder, handler.MustLogin)
-	server.POST("/api/v1/orders/changestatus", handler.ChangeStatus)
+	server.POST("/api/v1/orders/changestatus", handler.ChangeStatus, handler.MustLogin, handler.Idempotent)
 	server.POST("/api/v1/orders/get", handler.GetOrders, handler.MustLogin)
+	server.POST("/api/v1/orders/changeaddress", handler.ChangeOrderAddress, handler.MustLogin)
 
 	// category
 	server.POST("/api/v1/category/create", handler.CreateCategory)
 	server.GET("/api/v1/category/get", handler.GetCategory)
 
 	// carts
-	server.POST("/api/v1/carts/create", handler.CreateCarts, handler.MustLogin)
-	server.POST("/api/v1/carts/delete", handler.CartsDelete, handler.MustLogin)
-	server.POST("/api/v1/carts/alter", handler.AlterCartPro, handler.MustLogin)
+	server.POST("/api/v1/carts/create", handler.CreateCarts, handler.MustLogin, handler.Idempotent)
+	server.POST("/api/v1/carts/delete", handler.CartsDelete, handler.MustLogin, handler.Idempotent)
+	server.POST("/api/v1/carts/alter", handler.AlterCartPro, handler.MustLogin, handler.Idempotent)
 	server.GET("/api/v1/carts/getlist", handler.CartsBrowse, handler.MustLogin)
+
+	// admin
+	server.POST("/api/v1/admin/login", handler.AdminLogin, ratelimit.Limit("login", ratelimit.ByIP))
+	server.GET("/api/v1/admin/logout", handler.AdminLogout, handler.MustAdmin)
+	server.POST("/api/v1/admin/user/suspend", handler.SuspendUser, handler.MustAdmin)
+	server.POST("/api/v1/admin/user/reactivate", handler.ReactivateUser, handler.MustAdmin)
+	server.POST("/api/v1/admin/orders/list", handler.AdminListOrders, handler.MustAdmin)
+	server.POST("/api/v1/admin/orders/get", handler.AdminGetOrder, handler.MustAdmin)
+	server.POST("/api/v1/admin/orders/note", handler.AddOrderNote, handler.MustAdmin)
+	server.POST("/api/v1/admin/orders/changestatus", handler.AdminChangeOrders, handler.MustAdmin, handler.Idempotent)
+	server.POST("/api/v1/admin/orders/export", handler.ExportOrders, handler.MustAdmin)
+	server.POST("/api/v1/admin/reports/sales", handler.SalesReport, handler.MustAdmin)
+	server.POST("/api/v1/admin/reports/products", handler.TopProductsReport, handler.MustAdmin)
+	server.POST("/api/v1/admin/reports/categories", handler.TopCategoriesReport, handler.MustAdmin)
+	server.POST("/api/v1/admin/products/import", handler.ImportProducts, handler.MustAdmin)
+	server.POST("/api/v1/admin/products/importjob", handler.GetImportJob, handler.MustAdmin)
+	server.POST("/api/v1/admin/products/export", handler.ExportProducts, handler.MustAdmin)
+
+	// merchandising
+	server.POST("/api/v1/admin/slot/create", handler.CreateSlot, handler.MustAdmin)
+	server.POST("/api/v1/admin/slot/change", handler.ChangeSlot, handler.MustAdmin)
+	server.POST("/api/v1/admin/slot/delete", handler.DeleteSlot, handler.MustAdmin)
+	server.GET("/api/v1/admin/slot/get", handler.GetSlots, handler.MustAdmin)
+	server.POST("/api/v1/admin/slot/setitems", handler.SetSlotItems, handler.MustAdmin)
+
+	// health
+	server.GET("/healthz", handler.Healthz)
+	server.GET("/readyz", handler.Readyz)
 }
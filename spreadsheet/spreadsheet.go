@@ -0,0 +1,193 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// Package spreadsheet reads and writes the tables of bulk imports and
+// exports as CSV or as the first sheet of an XLSX workbook.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+
+	// MaxColumns is the widest row Read accepts.
+	MaxColumns = 256
+
+	// formulaPrefixes start cells which spreadsheet programs run as
+	// formulas when they open a CSV file.
+	formulaPrefixes = "=+-@\t\r"
+)
+
+var (
+	ErrUnknownFormat  = errors.New("format must be csv or xlsx")
+	ErrTooManyRows    = errors.New("file has too many rows")
+	ErrTooManyColumns = fmt.Errorf("file has rows of more than %d columns", MaxColumns)
+
+	// utf8BOM starts CSV files so spreadsheet programs read them as UTF-8.
+	utf8BOM = []byte("\xef\xbb\xbf")
+)
+
+// RowWriter writes a table row by row, Close finishes the file.
+type RowWriter interface {
+	Write(row []string) error
+	Close() error
+}
+
+// Format tells the format of a file by its name.
+func Format(filename string) (string, error) {
+	switch strings.ToLower(strings.TrimPrefix(path.Ext(filename), ".")) {
+	case CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// ContentType is the MIME type of a format.
+func ContentType(format string) string {
+	if format == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Read returns the rows of a file, at most maxRows of them. Empty rows of a
+// sheet are kept so row numbers match what the user sees, blank lines of a
+// CSV file are skipped.
+func Read(data []byte, format string, maxRows int) ([][]string, error) {
+	switch format {
+	case CSV:
+		return readCSV(data, maxRows)
+	case XLSX:
+		return readXLSX(data, maxRows)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func readCSV(data []byte, maxRows int) ([][]string, error) {
+	var rows [][]string
+
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	r.FieldsPerRecord = -1
+
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		if len(row) > MaxColumns {
+			return nil, ErrTooManyColumns
+		}
+
+		for i := range row {
+			row[i] = unquoteFormula(row[i])
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// NewWriter starts a file of the format on w.
+func NewWriter(w io.Writer, format string) (RowWriter, error) {
+	switch format {
+	case CSV:
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case XLSX:
+		return newXLSXWriter(w)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// QuoteFormula puts a quote before a cell which would be run as a formula,
+// spreadsheet programs show the rest as text. A cell already starting with
+// a quote gets another, so unquoteFormula can tell the two apart.
+func QuoteFormula(s string) string {
+	if s != "" && (s[0] == '\'' || strings.IndexByte(formulaPrefixes, s[0]) >= 0) {
+		return "'" + s
+	}
+
+	return s
+}
+
+// unquoteFormula takes off the quote QuoteFormula added, so an exported
+// file imports back unchanged.
+func unquoteFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && (s[1] == '\'' || strings.IndexByte(formulaPrefixes, s[1]) >= 0) {
+		return s[1:]
+	}
+
+	return s
+}
+
+// csvWriter quotes cells which would be run as formulas, cells of an XLSX
+// file are written as strings and are never run.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvWriter) Write(row []string) error {
+	quoted := make([]string, len(row))
+	for i, text := range row {
+		quoted[i] = QuoteFormula(text)
+	}
+
+	return cw.w.Write(quoted)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+
+	return cw.w.Error()
+}
@@ -0,0 +1,359 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize is the most a sheet or the shared strings of a workbook may
+// take once decompressed.
+const maxPartSize = 32 << 20
+
+var (
+	ErrNoSheet      = errors.New("xlsx workbook has no sheet")
+	ErrPartTooLarge = fmt.Errorf("xlsx sheet is larger than %d bytes", maxPartSize)
+)
+
+// Only what is needed to get cell text out of a sheet is decoded.
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	Text string         `xml:"t"`
+	Runs []xlsxTextItem `xml:"r"`
+}
+
+type xlsxTextItem struct {
+	Text string `xml:"t"`
+}
+
+type xlsxSheet struct {
+	Rows []xlsxRow `xml:"sheetData>row"`
+}
+
+type xlsxRow struct {
+	Number int        `xml:"r,attr"`
+	Cells  []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref    string       `xml:"r,attr"`
+	Type   string       `xml:"t,attr"`
+	Value  string       `xml:"v"`
+	Inline xlsxRichText `xml:"is"`
+}
+
+func (rt *xlsxRichText) String() string {
+	if len(rt.Runs) == 0 {
+		return rt.Text
+	}
+
+	var b strings.Builder
+	for _, r := range rt.Runs {
+		b.WriteString(r.Text)
+	}
+
+	return b.String()
+}
+
+func readXLSX(data []byte, maxRows int) ([][]string, error) {
+	var (
+		shared xlsxSharedStrings
+		sheet  xlsxSheet
+	)
+
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File, len(z.File))
+	var sheets []string
+	for _, f := range z.File {
+		files[f.Name] = f
+
+		if dir, _ := path.Split(f.Name); dir == "xl/worksheets/" && path.Ext(f.Name) == ".xml" {
+			sheets = append(sheets, f.Name)
+		}
+	}
+
+	if len(sheets) == 0 {
+		return nil, ErrNoSheet
+	}
+
+	// The first sheet is sheet1.xml in every workbook a spreadsheet program
+	// writes, fall back to the first name when it isn't there.
+	name := "xl/worksheets/sheet1.xml"
+	if files[name] == nil {
+		sort.Strings(sheets)
+		name = sheets[0]
+	}
+
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		if err = decodeZipXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = decodeZipXML(files[name], &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, r := range sheet.Rows {
+		// Row numbers are checked before the empty rows up to them are
+		// added, a single row may claim to be the millionth.
+		if r.Number > maxRows || len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		// Empty rows may be missing from the sheet.
+		for r.Number > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+
+		var row []string
+		for i, c := range r.Cells {
+			col := i
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+
+			if col >= MaxColumns {
+				return nil, ErrTooManyColumns
+			}
+
+			for len(row) < col {
+				row = append(row, "")
+			}
+
+			text, err := cellText(&c, &shared)
+			if err != nil {
+				return nil, err
+			}
+
+			row = append(row, text)
+		}
+
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// decodeZipXML decodes a part of the workbook, reading no more than
+// maxPartSize whatever size the archive claims for it.
+func decodeZipXML(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > maxPartSize {
+		return ErrPartTooLarge
+	}
+
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	lr := &io.LimitedReader{R: r, N: maxPartSize + 1}
+
+	err = xml.NewDecoder(lr).Decode(v)
+	if lr.N == 0 {
+		return ErrPartTooLarge
+	}
+
+	return err
+}
+
+func cellText(c *xlsxCell, shared *xlsxSharedStrings) (string, error) {
+	switch c.Type {
+	case "s":
+		i, err := strconv.Atoi(c.Value)
+		if err != nil || i < 0 || i >= len(shared.Items) {
+			return "", fmt.Errorf("xlsx cell %s: bad shared string %q", c.Ref, c.Value)
+		}
+
+		return shared.Items[i].String(), nil
+	case "inlineStr":
+		return c.Inline.String(), nil
+	case "b":
+		if c.Value == "1" {
+			return "TRUE", nil
+		}
+
+		return "FALSE", nil
+	}
+
+	return c.Value, nil
+}
+
+// columnIndex turns the letters of a cell reference like "AB12" into a
+// column number counted from 0.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+
+		col = col*26 + int(ch-'A') + 1
+		n++
+
+		if col > MaxColumns {
+			return 0, ErrTooManyColumns
+		}
+	}
+
+	if n == 0 {
+		return 0, fmt.Errorf("xlsx cell reference %q", ref)
+	}
+
+	return col - 1, nil
+}
+
+func columnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+
+	return name
+}
+
+// The parts of a workbook with one sheet, the sheet itself is streamed.
+var xlsxParts = []struct {
+	name, body string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter writes every cell as an inline string, numbers included, so
+// what is exported reads back unchanged.
+type xlsxWriter struct {
+	z     *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	z := zip.NewWriter(w)
+
+	for _, part := range xlsxParts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxWriter{z: z, sheet: sheet}, nil
+}
+
+func (xw *xlsxWriter) Write(row []string) error {
+	var b bytes.Buffer
+
+	xw.rows++
+	fmt.Fprintf(&b, `<row r="%d">`, xw.rows)
+
+	for i, text := range row {
+		if text == "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(i), xw.rows)
+		if err := xml.EscapeText(&b, []byte(text)); err != nil {
+			return err
+		}
+		b.WriteString(`</t></is></c>`)
+	}
+
+	b.WriteString(`</row>`)
+
+	_, err := xw.sheet.Write(b.Bytes())
+
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if _, err := io.WriteString(xw.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+
+	return xw.z.Close()
+}
@@ -0,0 +1,122 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// workbook builds an xlsx file holding only a first sheet with sheetData.
+func workbook(t *testing.T, sheetData string) []byte {
+	var buf bytes.Buffer
+
+	z := zip.NewWriter(&buf)
+	f, err := z.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = f.Write([]byte("<worksheet><sheetData>" + sheetData + "</sheetData></worksheet>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = z.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+
+	want := [][]string{{"name", "price"}, nil, {"", "12.5"}, {"<a & b>", "1"}}
+
+	w, err := NewWriter(&buf, XLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range want {
+		if err = w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(buf.Bytes(), XLSX, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read = %q, want %q", got, want)
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   []byte
+		format string
+		want   error
+	}{
+		{"row number", workbook(t, `<row r="1000000"><c r="A1000000"><v>1</v></c></row>`), XLSX, ErrTooManyRows},
+		{"rows", workbook(t, strings.Repeat("<row><c><v>1</v></c></row>", 4)), XLSX, ErrTooManyRows},
+		{"column", workbook(t, `<row r="1"><c r="XFD1"><v>1</v></c></row>`), XLSX, ErrTooManyColumns},
+		{"column overflow", workbook(t, `<row r="1"><c r="ZZZZZZZZZZZZZZZZ1"><v>1</v></c></row>`), XLSX, ErrTooManyColumns},
+		{"sheet size", workbook(t, strings.Repeat(" ", maxPartSize)), XLSX, ErrPartTooLarge},
+		{"csv rows", []byte("a\nb\nc\nd\n"), CSV, ErrTooManyRows},
+		{"csv columns", []byte(strings.Repeat("a,", MaxColumns) + "a\n"), CSV, ErrTooManyColumns},
+	}
+
+	for _, c := range cases {
+		if _, err := Read(c.data, c.format, 3); err != c.want {
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	rows, err := Read(workbook(t, `<row r="3"><c r="B3"><v>1</v></c></row>`), XLSX, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := [][]string{nil, nil, {"", "1"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("Read = %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// backfillorderno gives orders placed before order numbers existed a number
+// made from the time they were placed. Orders which already have a number
+// are skipped, so the tool can be run again if it is interrupted. Run it
+// before adding the unique index on orderno.
+//
+//	$ cd ShopApi/tools/backfillorderno
+//	$ go build
+//	$ ./backfillorderno -config ../../server -dry-run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/spf13/viper"
+
+	"ShopApi/models"
+	"ShopApi/orm"
+	"ShopApi/utility"
+)
+
+const batchSize = 500
+
+var (
+	configPath = flag.String("config", "./", "directory of config.json")
+	dryRun     = flag.Bool("dry-run", false, "report what would be filled without writing")
+)
+
+func main() {
+	flag.Parse()
+
+	viper.AddConfigPath(*configPath)
+	viper.SetConfigName("config")
+
+	if err := viper.ReadInConfig(); err != nil {
+		exit(err)
+	}
+
+	conf := fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8&parseTime=True&loc=Local",
+		viper.GetString("mysql.user"), viper.GetString("mysql.pass"),
+		viper.GetString("mysql.host"), viper.GetString("mysql.port"), viper.GetString("mysql.db"))
+	orm.InitOrm(conf)
+
+	var (
+		lastKey uint64
+		total   int
+		batch   []models.Orders
+	)
+
+	// Numbers given out in this run, the random part can repeat for orders
+	// placed in the same second.
+	used := make(map[string]bool)
+
+	for {
+		batch = nil
+
+		err := orm.Conn.Where("orderno = '' AND id > ?", lastKey).Order("id").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			exit(err)
+		}
+
+		for _, order := range batch {
+			total++
+			lastKey = order.ID
+
+			no, err := utility.OrderNo(order.Created)
+			for err == nil && used[no] {
+				no, err = utility.OrderNo(order.Created)
+			}
+
+			if err != nil {
+				exit(err)
+			}
+
+			used[no] = true
+
+			if *dryRun {
+				fmt.Printf("order %d: %s\n", order.ID, no)
+				continue
+			}
+
+			err = orm.Conn.Model(&models.Orders{}).Where("id = ?", order.ID).Update("orderno", no).Error
+			if err != nil {
+				exit(fmt.Errorf("order %d: %v", order.ID, err))
+			}
+		}
+
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	fmt.Printf("orders: %d, dry run: %v\n", total, *dryRun)
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "backfillorderno:", err)
+	os.Exit(1)
+}
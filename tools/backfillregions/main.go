@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// backfillregions fills the province, city and district codes of addresses
+// saved before they existed by parsing their area. Addresses which already
+// have codes are skipped and areas which can't be placed are left alone, so
+// the tool can be run again after loading a more complete dataset.
+//
+//	$ cd ShopApi/tools/backfillregions
+//	$ go build
+//	$ ./backfillregions -config ../../server -dry-run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/spf13/viper"
+
+	"ShopApi/models"
+	"ShopApi/orm"
+	"ShopApi/region"
+)
+
+const batchSize = 500
+
+var (
+	configPath = flag.String("config", "./", "directory of config.json")
+	dryRun     = flag.Bool("dry-run", false, "report what would be filled without writing")
+)
+
+func main() {
+	flag.Parse()
+
+	viper.AddConfigPath(*configPath)
+	viper.SetConfigName("config")
+
+	if err := viper.ReadInConfig(); err != nil {
+		exit(err)
+	}
+
+	if file := viper.GetString("region.file"); file != "" {
+		if err := region.LoadFile(file); err != nil {
+			exit(err)
+		}
+	}
+
+	conf := fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8&parseTime=True&loc=Local",
+		viper.GetString("mysql.user"), viper.GetString("mysql.pass"),
+		viper.GetString("mysql.host"), viper.GetString("mysql.port"), viper.GetString("mysql.db"))
+	orm.InitOrm(conf)
+
+	var (
+		lastKey       uint64
+		total, filled int
+		batch         []models.Address
+	)
+
+	for {
+		batch = nil
+
+		err := orm.Conn.Where("province = '' AND id > ?", lastKey).Order("id").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			exit(err)
+		}
+
+		for _, addr := range batch {
+			total++
+			lastKey = addr.ID
+
+			m := region.Parse(addr.Area)
+			if m.Province == "" {
+				fmt.Printf("address %d: can't place %q\n", addr.ID, addr.Area)
+				continue
+			}
+
+			filled++
+
+			if *dryRun {
+				continue
+			}
+
+			updater := map[string]interface{}{
+				"province": m.Province,
+				"city":     m.City,
+				"district": m.District,
+			}
+
+			err = orm.Conn.Model(&models.Address{}).Where("id = ?", addr.ID).Updates(updater).Error
+			if err != nil {
+				exit(fmt.Errorf("address %d: %v", addr.ID, err))
+			}
+		}
+
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	fmt.Printf("addresses: %d, filled: %d, unplaced: %d, dry run: %v\n", total, filled, total-filled, *dryRun)
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "backfillregions:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,98 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// buildreports builds the sales reports of past days. The server only
+// keeps the last few days up to date, run this once after deploying the
+// reports, or to rebuild a range after fixing orders by hand.
+//
+//	$ cd ShopApi/tools/buildreports
+//	$ go build
+//	$ ./buildreports -config ../../server -from 2017-08-01 -to 2026-10-18
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/spf13/viper"
+
+	"ShopApi/models"
+	"ShopApi/orm"
+)
+
+var (
+	configPath = flag.String("config", "./", "directory of config.json")
+	from       = flag.String("from", "", "first day to build, like 2017-08-01")
+	to         = flag.String("to", time.Now().Format(models.ReportDate), "last day to build")
+)
+
+func main() {
+	flag.Parse()
+
+	first, err := time.ParseInLocation(models.ReportDate, *from, time.Local)
+	if err != nil {
+		exit(fmt.Errorf("-from: %v", err))
+	}
+
+	last, err := time.ParseInLocation(models.ReportDate, *to, time.Local)
+	if err != nil {
+		exit(fmt.Errorf("-to: %v", err))
+	}
+
+	viper.AddConfigPath(*configPath)
+	viper.SetConfigName("config")
+
+	if err := viper.ReadInConfig(); err != nil {
+		exit(err)
+	}
+
+	conf := fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8&parseTime=True&loc=Local",
+		viper.GetString("mysql.user"), viper.GetString("mysql.pass"),
+		viper.GetString("mysql.host"), viper.GetString("mysql.port"), viper.GetString("mysql.db"))
+	orm.InitOrm(conf)
+
+	days := 0
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if err := models.ReportService.Refresh(day, day); err != nil {
+			exit(err)
+		}
+
+		days++
+	}
+
+	fmt.Printf("days built: %d\n", days)
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "buildreports:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,198 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+// migratemedia copies product images, sizes and colors from the Mongo
+// collections into the MySQL tables used by the "mysql" media store.
+// Each kind of media is copied only when the product has none of it in
+// MySQL yet, so the tool can be run again after a partial failure.
+//
+//	$ cd ShopApi/tools/migratemedia
+//	$ go build
+//	$ ./migratemedia -config ../../server -dry-run
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/spf13/viper"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"ShopApi/models"
+	"ShopApi/orm"
+)
+
+var (
+	configPath = flag.String("config", "./", "directory of config.json")
+	dryRun     = flag.Bool("dry-run", false, "report what would be copied without writing")
+)
+
+func main() {
+	flag.Parse()
+
+	viper.AddConfigPath(*configPath)
+	viper.SetConfigName("config")
+
+	if err := viper.ReadInConfig(); err != nil {
+		exit(err)
+	}
+
+	conf := fmt.Sprintf("%s:%s@tcp(%s%s)/%s?charset=utf8&parseTime=True&loc=Local",
+		viper.GetString("mysql.user"), viper.GetString("mysql.pass"),
+		viper.GetString("mysql.host"), viper.GetString("mysql.port"), viper.GetString("mysql.db"))
+	orm.InitOrm(conf)
+
+	session, err := mgo.DialWithTimeout(viper.GetString("mongodb.url"), 5*time.Second)
+	if err != nil {
+		exit(err)
+	}
+	defer session.Close()
+
+	var ids []uint64
+	err = orm.Conn.Model(&models.Product{}).Order("id").Pluck("id", &ids).Error
+	if err != nil {
+		exit(err)
+	}
+
+	var copied, skipped int
+	for _, id := range ids {
+		done, err := migrate(session.DB(orm.MD), id)
+		if err != nil {
+			exit(fmt.Errorf("product %d: %v", id, err))
+		}
+
+		if done {
+			copied++
+		} else {
+			skipped++
+		}
+	}
+
+	fmt.Printf("products: %d, copied: %d, skipped: %d, dry run: %v\n", len(ids), copied, skipped, *dryRun)
+}
+
+// hasRows reports whether the product already has rows of model in MySQL.
+func hasRows(model interface{}, productID uint64) (bool, error) {
+	var count int
+
+	err := orm.Conn.Model(model).Where("productid = ?", productID).Count(&count).Error
+
+	return count > 0, err
+}
+
+// migrate copies the media of one product in a single transaction, each
+// kind only when MySQL has none of it. It returns false when every kind is
+// already there.
+func migrate(md *mgo.Database, productID uint64) (done bool, err error) {
+	var (
+		images []models.ProductImages
+		sizes  []models.ProductSize
+		colors []models.ProductColor
+	)
+
+	kinds := []struct {
+		model      interface{}
+		collection string
+		result     interface{}
+	}{
+		{&models.ProductImageRow{}, "productimage", &images},
+		{&models.ProductSizeRow{}, "productsize", &sizes},
+		{&models.ProductColorRow{}, "productcolors", &colors},
+	}
+
+	query := bson.M{"productid": productID}
+
+	for _, kind := range kinds {
+		has, err := hasRows(kind.model, productID)
+		if err != nil {
+			return false, err
+		}
+
+		if has {
+			continue
+		}
+
+		if err = md.C(kind.collection).Find(query).All(kind.result); err != nil {
+			return false, err
+		}
+
+		done = true
+	}
+
+	if !done {
+		return false, nil
+	}
+
+	fmt.Printf("product %d: %d images, %d sizes, %d colors\n", productID, len(images), len(sizes), len(colors))
+
+	if *dryRun {
+		return true, nil
+	}
+
+	tx := orm.Conn.Begin()
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit().Error
+		}
+	}()
+
+	for _, image := range images {
+		err = tx.Create(&models.ProductImageRow{ProductID: productID, Class: image.Class, Image: image.Image}).Error
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, size := range sizes {
+		err = tx.Create(&models.ProductSizeRow{ProductID: productID, Size: size.Size}).Error
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for _, color := range colors {
+		err = tx.Create(&models.ProductColorRow{ProductID: productID, Color: color.Color}).Error
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "migratemedia:", err)
+	os.Exit(1)
+}
@@ -25,38 +25,71 @@
 /*
  * Revision History:
  *     Initial: 2017/07/19        Sun Anxiang
+ *     Modify : 2026/10/19
  */
 
 package utility
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
 	"fmt"
-	"math/rand"
+	"math/big"
 	"time"
 
 	"ShopApi/server/initcache"
 )
 
+// CodeLifetime is how long a code sent by SMS can be used.
+const CodeLifetime = 5 * time.Minute
+
+// CreateCode saves a new code for phone and sends it with the SMSSender.
 func CreateCode(phone string) error {
-	code := GenerateCode()
+	code, err := GenerateCode()
+	if err != nil {
+		return err
+	}
 
-	err := initcache.Bm.Put(phone, code, 60*time.Second)
-	if err == nil {
-		fmt.Println("code", code)
+	err = initcache.Bm.Put(phone, code, CodeLifetime)
+	if err != nil {
+		return err
+	}
+
+	err = sendSMS(phone, code)
+	if err != nil {
+		initcache.Bm.Delete(phone)
 	}
 
 	return err
 }
 
+// VerifyCode checks the code last sent to phone, a code works only once.
 func VerifyCode(phone, code string) bool {
-	codeSaved := initcache.Bm.Get(phone)
+	var saved string
+
+	switch v := initcache.Bm.Get(phone).(type) {
+	case string:
+		saved = v
+	case []byte:
+		saved = string(v)
+	default:
+		return false
+	}
+
+	if saved == "" || subtle.ConstantTimeCompare([]byte(saved), []byte(code)) != 1 {
+		return false
+	}
 
-	return code == codeSaved
+	initcache.Bm.Delete(phone)
+
+	return true
 }
 
-func GenerateCode() string {
-	num := rand.New(rand.NewSource(time.Now().UnixNano()))
-	code := fmt.Sprintf("%06v", num.Int31n(999999))
+func GenerateCode() (string, error) {
+	num, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
 
-	return code
+	return fmt.Sprintf("%06d", num.Int64()), nil
 }
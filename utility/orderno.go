@@ -0,0 +1,94 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package utility
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// OrderNoLength is the length of an order number: the date, the second of
+// the day, four random digits and a check digit.
+const OrderNoLength = 18
+
+var orderNoRandom = big.NewInt(10000)
+
+// OrderNo returns a new order number for an order placed at t. Numbers sort
+// by the time they were made, the random part keeps them from being guessed
+// or counted, callers still have to make sure they are unique.
+func OrderNo(t time.Time) (string, error) {
+	n, err := rand.Int(rand.Reader, orderNoRandom)
+	if err != nil {
+		return "", err
+	}
+
+	seconds := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	no := fmt.Sprintf("%s%05d%04d", t.Format("20060102"), seconds, n.Int64())
+
+	return no + string(luhnDigit(no)), nil
+}
+
+// ValidOrderNo reports whether no looks like an order number, the check
+// digit catches most mistyped numbers before they reach the database.
+func ValidOrderNo(no string) bool {
+	if len(no) != OrderNoLength {
+		return false
+	}
+
+	for i := 0; i < len(no); i++ {
+		if no[i] < '0' || no[i] > '9' {
+			return false
+		}
+	}
+
+	return luhnDigit(no[:OrderNoLength-1]) == no[OrderNoLength-1]
+}
+
+func luhnDigit(digits string) byte {
+	sum := 0
+	double := true
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
@@ -25,10 +25,116 @@
 /*
  * Revision History:
  *     Initial: 2017/07/24        Li Zebang
+ *     Modify : 2026/10/19
  */
 
 package utility
 
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"ShopApi/general"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	cursorPrefix = "k:"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Pagination is the paging part of a list request. Clients either send page
+// and pagesize, or the cursor returned with the previous page. Lists are
+// ordered by their key column so a cursor is the last key already seen.
+type Pagination struct {
+	Page     uint64 `json:"page" query:"page"`
+	PageSize uint64 `json:"pagesize" query:"pagesize"`
+	Cursor   string `json:"cursor" query:"cursor"`
+
+	after string
+}
+
 func Paging(page, pageSize uint64) (pageStart uint64) {
+	if page == 0 {
+		return 0
+	}
+
 	return (page - 1) * pageSize
 }
+
+// Normalize fills in defaults, caps the page size and decodes the cursor.
+func (p *Pagination) Normalize() error {
+	if p.PageSize == 0 {
+		p.PageSize = DefaultPageSize
+	}
+
+	if p.PageSize > MaxPageSize {
+		p.PageSize = MaxPageSize
+	}
+
+	if p.Page == 0 {
+		p.Page = 1
+	}
+
+	if p.Cursor == "" {
+		p.after = ""
+		return nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) || len(raw) == len(cursorPrefix) {
+		return ErrInvalidCursor
+	}
+
+	p.after = string(raw[len(cursorPrefix):])
+
+	return nil
+}
+
+// After is the last key of the previous page, empty when paging by number.
+func (p *Pagination) After() string {
+	return p.after
+}
+
+// Offset is the number of rows to skip, a cursor already positions the page.
+func (p *Pagination) Offset() uint64 {
+	if p.after != "" {
+		return 0
+	}
+
+	return Paging(p.Page, p.PageSize)
+}
+
+// Fetch is the number of rows to query, one more than the page size tells
+// whether there is a next page.
+func (p *Pagination) Fetch() uint64 {
+	return p.PageSize + 1
+}
+
+// Trim returns how many of the n fetched rows belong to the page.
+func (p *Pagination) Trim(n int) (keep int, hasMore bool) {
+	if uint64(n) > p.PageSize {
+		return int(p.PageSize), true
+	}
+
+	return n, false
+}
+
+// Info builds the paging part of the response, lastKey is the key of the
+// last row on the page.
+func (p *Pagination) Info(total uint64, hasMore bool, lastKey string) *general.PageInfo {
+	info := &general.PageInfo{
+		Total:   total,
+		HasMore: hasMore,
+	}
+
+	if hasMore {
+		info.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + lastKey))
+	}
+
+	return info
+}
@@ -25,6 +25,7 @@
 /*
  * Revision History:
  *     Initial: 2017/07/19        Yusan Kurban
+ *     Modify : 2026/10/19
  */
 
 package utility
@@ -39,6 +40,6 @@ import (
 var GlobalSessions *session.Manager
 
 func init() {
-	GlobalSessions, _ = session.NewManager("memory", general.SessionUserID, 3600)
+	GlobalSessions, _ = session.NewManager("memory", general.SessionUserID, general.SessionMaxLifetime)
 	go GlobalSessions.GC()
 }
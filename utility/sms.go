@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2017 SmartestEE Inc.
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ */
+
+/*
+ * Revision History:
+ *     Initial: 2026/10/19
+ */
+
+package utility
+
+import (
+	"errors"
+	"sync"
+
+	"ShopApi/log"
+)
+
+var ErrNoSMSSender = errors.New("no SMS sender configured")
+
+// SMSSender delivers verification codes to phones, the SMS gateway is
+// plugged in with SetSMSSender.
+type SMSSender interface {
+	Send(phone, code string) error
+}
+
+var (
+	smsSenderLock sync.RWMutex
+	smsSender     SMSSender
+)
+
+// SetSMSSender sets where CreateCode sends codes, nil turns sending off.
+func SetSMSSender(sender SMSSender) {
+	smsSenderLock.Lock()
+	defer smsSenderLock.Unlock()
+
+	smsSender = sender
+}
+
+func sendSMS(phone, code string) error {
+	smsSenderLock.RLock()
+	sender := smsSender
+	smsSenderLock.RUnlock()
+
+	if sender == nil {
+		return ErrNoSMSSender
+	}
+
+	return sender.Send(phone, code)
+}
+
+// DevSMSSender writes codes to the log instead of texting them, it is only
+// used by servers running with server.debug.
+type DevSMSSender struct{}
+
+func (DevSMSSender) Send(phone, code string) error {
+	tail := phone
+	if len(tail) > 4 {
+		tail = tail[len(tail)-4:]
+	}
+
+	log.Logger.Warn("[DEV] SMS code %s for the phone ending in %s", code, tail)
+
+	return nil
+}
@@ -20,7 +20,9 @@ CREATE TABLE IF NOT EXISTS `user` (
   `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
   `name` varchar(20) UNIQUE DEFAULT NULL,
   `password` varchar(128) NOT NULL DEFAULT '',
-  `status` int(8) DEFAULT NULL,
+  `status` int(8) DEFAULT NULL COMMENT '0: 正常, 1: 停用, 2: 锁定, 3: 已注销',
+  `failedlogins` int(16) NOT NULL DEFAULT '0' COMMENT '连续登录失败次数',
+  `lockeduntil` datetime DEFAULT NULL,
   `created` datetime NOT NULL DEFAULT current_timestamp,
   `updated` datetime DEFAULT NULL,
   PRIMARY KEY (`id`)
@@ -37,22 +39,28 @@ CREATE TABLE IF NOT EXISTS `userinfo` (
 
 
 CREATE TABLE IF NOT EXISTS `address` (
-  `id` int(16) NOT NULL DEFAULT '',
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
   `name` varchar(64) NOT NULL,
   `userid` int(16) NOT NULL,
   `phone` varchar(16) NOT NULL,
   `area` varchar(256) NOT NULL,
+  `province` char(6) NOT NULL DEFAULT '' COMMENT 'GB/T 2260 省级代码',
+  `city` char(6) NOT NULL DEFAULT '' COMMENT '市级代码',
+  `district` char(6) NOT NULL DEFAULT '' COMMENT '区县代码',
   `address` varchar(256) NOT NULL,
   `created` datetime NOT NULL DEFAULT current_timestamp,
   `updated` datetime DEFAULT NULL,
   `isdefault` int(8) DEFAULT NULL,
-  PRIMARY KEY (`id`)
+  PRIMARY KEY (`id`),
+  KEY `idx_userid` (`userid`)
 ) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 
 CREATE TABLE IF NOT EXISTS `orderproduct` (
   `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
   `productid` int(16) NOT NULL,
   `orderid` int(16) DEFAULT '0',
+  `name` varchar(128) NOT NULL DEFAULT '' COMMENT '下单时的商品名',
+  `price` double NOT NULL DEFAULT '0' COMMENT '下单时的单价',
   `discount`  int(8)  NOT NULL ,
   `size`  varchar(64) NOT NULL ,
   `count` int(64) NOT NULL ,
@@ -62,18 +70,50 @@ CREATE TABLE IF NOT EXISTS `orderproduct` (
 
 CREATE TABLE IF NOT EXISTS `orders` (
   `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `orderno` char(18) NOT NULL DEFAULT '' COMMENT '订单号',
   `userid` int(16) NOT NULL,
   `addressid` varchar(64) NOT NULL,
+  `receiver` varchar(64) NOT NULL DEFAULT '' COMMENT '下单时的收货地址',
+  `phone` varchar(16) NOT NULL DEFAULT '',
+  `area` varchar(256) NOT NULL DEFAULT '',
+  `province` char(6) NOT NULL DEFAULT '',
+  `city` char(6) NOT NULL DEFAULT '',
+  `district` char(6) NOT NULL DEFAULT '',
+  `address` varchar(256) NOT NULL DEFAULT '',
   `totalprice` double NOT NULL COMMENT '商品总价',
   `freight` double DEFAULT '0' COMMENT '运费',
   `remark` text COMMENT '备注',
   `status` int(8) NOT NULL,
   `payway` int NOT NULL ,
+  `paid` datetime DEFAULT NULL,
+  `carrier` varchar(64) NOT NULL DEFAULT '',
+  `trackingno` varchar(64) NOT NULL DEFAULT '',
+  `shipped` datetime DEFAULT NULL,
   `created` datetime NOT NULL DEFAULT current_timestamp,
   `updated` datetime DEFAULT NULL,
-  PRIMARY KEY (`id`)  
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_orderno` (`orderno`)
 ) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
 
+CREATE TABLE IF NOT EXISTS `orderhistory` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `orderid` int(16) unsigned NOT NULL,
+  `status` int(8) NOT NULL,
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_orderid` (`orderid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+CREATE TABLE IF NOT EXISTS `ordernote` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `orderid` int(16) unsigned NOT NULL,
+  `adminid` int(16) unsigned NOT NULL,
+  `note` text NOT NULL COMMENT '内部备注，用户不可见',
+  `created` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_orderid` (`orderid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
 
 CREATE TABLE IF NOT EXISTS `cart` (
   `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
@@ -86,7 +126,7 @@ CREATE TABLE IF NOT EXISTS `cart` (
   `color`   varchar(64) DEFAULT '',
   `price`   double NOT NULL,
   `status`  int(8) NOT NULL DEFAULT '233' COMMENT'是否在购物车  0: 在, 1: 不在',
-  `paystatus`  int(8) NOT NULL DEFAULT '236' COMMENT'是否购买  0: 购买, 1: 不购买',
+  `paystatus`  int(8) NOT NULL DEFAULT '236' COMMENT'是否购买  0: 未购买, 1: 已购买',
   `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
@@ -108,8 +148,167 @@ CREATE TABLE IF NOT EXISTS `product` (
   `totalsale` int(16) NOT NULL DEFAULT '0' COMMENT'销售量',
   `category` int(16) NOT NULL,
   `price` double NOT NULL,
+  `stock` int(16) unsigned NOT NULL DEFAULT '0' COMMENT '库存',
   `detail` varchar(1024) DEFAULT '',
   `status` int(8) NOT NULL,
   `created` datetime NOT NULL DEFAULT current_timestamp,
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `productimage` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `productid` int(16) unsigned NOT NULL,
+  `class` int(8) NOT NULL COMMENT '0: 头像, 1: 图片, 2: 详情图片',
+  `image` varchar(512) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_productid_class` (`productid`, `class`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `productsize` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `productid` int(16) unsigned NOT NULL,
+  `size` varchar(64) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_productid` (`productid`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `productcolor` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `productid` int(16) unsigned NOT NULL,
+  `color` varchar(64) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_productid` (`productid`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `slot` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL DEFAULT '',
+  `page` varchar(32) NOT NULL COMMENT 'home, mypage',
+  `kind` int(8) NOT NULL COMMENT '0: 轮播图, 1: 推荐商品, 2: 分类入口',
+  `sort` int(16) NOT NULL DEFAULT '0',
+  `status` int(8) NOT NULL DEFAULT '0' COMMENT '0: 启用, 1: 停用',
+  `startat` datetime DEFAULT NULL,
+  `endat` datetime DEFAULT NULL,
+  `created` datetime NOT NULL DEFAULT current_timestamp,
+  `updated` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_page_kind` (`page`, `kind`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `slotitem` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `slotid` int(16) unsigned NOT NULL,
+  `productid` int(16) unsigned NOT NULL DEFAULT '0',
+  `categoryid` int(16) unsigned NOT NULL DEFAULT '0',
+  `image` varchar(512) NOT NULL DEFAULT '',
+  `sort` int(16) NOT NULL DEFAULT '0',
+  PRIMARY KEY (`id`),
+  KEY `idx_slotid` (`slotid`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `idempotency` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `scope` varchar(64) NOT NULL COMMENT '用户 ID 或 IP',
+  `idemkey` varchar(64) NOT NULL COMMENT 'Idempotency-Key 请求头',
+  `fingerprint` varchar(64) NOT NULL,
+  `status` int(8) NOT NULL DEFAULT '0' COMMENT '0: 处理中, 其余为 HTTP 状态码',
+  `contenttype` varchar(128) NOT NULL DEFAULT '',
+  `body` mediumtext,
+  `created` datetime NOT NULL DEFAULT current_timestamp,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_scope_idemkey` (`scope`, `idemkey`),
+  KEY `idx_created` (`created`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `useridentity` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `provider` varchar(32) NOT NULL COMMENT 'wechat 等',
+  `externalid` varchar(128) NOT NULL COMMENT '第三方账号 ID，微信为 unionid 或 openid',
+  `userid` int(16) unsigned NOT NULL,
+  `nickname` varchar(100) NOT NULL DEFAULT '',
+  `avatar` varchar(512) NOT NULL DEFAULT '',
+  `created` datetime NOT NULL DEFAULT current_timestamp,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_provider_externalid` (`provider`, `externalid`),
+  KEY `idx_userid` (`userid`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `usersession` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `userid` int(16) unsigned NOT NULL,
+  `device` varchar(64) NOT NULL DEFAULT '' COMMENT 'X-Device-Name 请求头',
+  `ip` varchar(64) NOT NULL DEFAULT '',
+  `useragent` varchar(255) NOT NULL DEFAULT '',
+  `created` datetime NOT NULL DEFAULT current_timestamp,
+  `lastseen` datetime NOT NULL DEFAULT current_timestamp,
+  PRIMARY KEY (`id`),
+  KEY `idx_userid_lastseen` (`userid`, `lastseen`),
+  KEY `idx_lastseen` (`lastseen`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `usertotp` (
+  `userid` int(16) unsigned NOT NULL,
+  `secret` varchar(64) NOT NULL COMMENT 'base32 编码',
+  `enabled` tinyint(1) NOT NULL DEFAULT 0 COMMENT '输入验证码确认后开启',
+  `laststep` bigint(20) NOT NULL DEFAULT 0 COMMENT '最后使用的时间片，防止验证码重复使用',
+  `created` datetime NOT NULL DEFAULT current_timestamp,
+  `updated` datetime NOT NULL DEFAULT current_timestamp,
+  PRIMARY KEY (`userid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+
+CREATE TABLE IF NOT EXISTS `userbackupcode` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `userid` int(16) unsigned NOT NULL,
+  `code` char(64) NOT NULL COMMENT '备用码的 sha256',
+  `used` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_userid_code` (`userid`, `code`)
+) ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+CREATE TABLE IF NOT EXISTS `reportdaily` (
+  `day` date NOT NULL,
+  `orders` int(16) unsigned NOT NULL DEFAULT '0' COMMENT '当天下单数',
+  `paidorders` int(16) unsigned NOT NULL DEFAULT '0' COMMENT '其中已支付的订单数',
+  `gmv` double NOT NULL DEFAULT '0' COMMENT '已支付订单总额',
+  `refunds` int(16) unsigned NOT NULL DEFAULT '0' COMMENT '支付后退货或取消的订单数',
+  `orderusers` int(16) unsigned NOT NULL DEFAULT '0',
+  `cartusers` int(16) unsigned NOT NULL DEFAULT '0' COMMENT '当天加购的用户数',
+  `convertedusers` int(16) unsigned NOT NULL DEFAULT '0' COMMENT '其中下单购买加购商品的用户数',
+  `updated` datetime NOT NULL,
+  PRIMARY KEY (`day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+CREATE TABLE IF NOT EXISTS `reportproduct` (
+  `day` date NOT NULL,
+  `productid` int(16) unsigned NOT NULL,
+  `category` int(16) unsigned NOT NULL DEFAULT '0',
+  `quantity` int(16) unsigned NOT NULL DEFAULT '0',
+  `amount` double NOT NULL DEFAULT '0',
+  PRIMARY KEY (`day`, `productid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;
+
+CREATE TABLE IF NOT EXISTS `importjob` (
+  `id` int(16) unsigned NOT NULL AUTO_INCREMENT,
+  `adminid` int(16) unsigned NOT NULL,
+  `filename` varchar(256) NOT NULL DEFAULT '',
+  `dryrun` tinyint(1) NOT NULL DEFAULT '0',
+  `status` int(8) NOT NULL COMMENT '0: 排队, 1: 进行中, 2: 完成, 3: 失败',
+  `message` varchar(1024) NOT NULL DEFAULT '',
+  `total` int(16) NOT NULL DEFAULT '0',
+  `succeeded` int(16) NOT NULL DEFAULT '0',
+  `failed` int(16) NOT NULL DEFAULT '0',
+  `errors` mediumtext COMMENT '出错的行，JSON',
+  `created` datetime NOT NULL,
+  `finished` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin;